@@ -70,7 +70,7 @@ type dynamicConfigInternal struct {
 	options       Options
 	configMapper  ConfigMapper
 	// Internal
-	client        *kubernetes.Clientset
+	client        kubernetes.Interface
 	lock          sync.Mutex
 	refreshCancel chan struct{}
 	// Recently processed data
@@ -79,8 +79,13 @@ type dynamicConfigInternal struct {
 }
 
 func NewDynamicConfig(config *rest.Config, configMapName string, configMapper ConfigMapper, options Options) (DynamicConfig, error) {
+	return NewDynamicConfigFromClientset(kubernetes.NewForConfigOrDie(config), configMapName, configMapper, options)
+}
+
+// NewDynamicConfigFromClientset is NewDynamicConfig with an existing clientset, such as a fake clientset in tests
+func NewDynamicConfigFromClientset(clientSet kubernetes.Interface, configMapName string, configMapper ConfigMapper, options Options) (DynamicConfig, error) {
 	internal := dynamicConfigInternal{
-		client:        kubernetes.NewForConfigOrDie(config),
+		client:        clientSet,
 		configMapName: configMapName,
 		options:       options,
 		configMapper:  configMapper,
@@ -96,7 +101,7 @@ func NewDynamicConfig(config *rest.Config, configMapName string, configMapper Co
 	internal.bootstrap = internal.current
 
 	if !options.DisableDynamicUpdates {
-		internal.startRefreshProcess(kubernetes.NewForConfigOrDie(config))
+		internal.startRefreshProcess(clientSet)
 	}
 
 	return &internal, nil
@@ -202,7 +207,7 @@ func (d *dynamicConfigInternal) updateConfigMap(configMapsClient v1.ConfigMapInt
 	return updated.Data
 }
 
-func (d *dynamicConfigInternal) startRefreshProcess(clientSet *kubernetes.Clientset) {
+func (d *dynamicConfigInternal) startRefreshProcess(clientSet kubernetes.Interface) {
 	informerFactory := informers.NewSharedInformerFactoryWithOptions(clientSet, time.Minute)
 	configMapInformer := informerFactory.Core().V1().ConfigMaps().Informer()
 	configMapInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -0,0 +1,238 @@
+// Package instancetype is an offline catalog of the EC2 instance types Titus runs on
+package instancetype
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Netflix/titus-kube-common/node"
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// MinAllocatableMemoryFraction is the smallest share of an instance type's memory a node may report as
+// allocatable. The rest is reserved for the OS and the Titus agents.
+const MinAllocatableMemoryFraction = 0.8
+
+//go:embed catalog.json
+var catalogJSON []byte
+
+var catalog = mustLoadCatalog(catalogJSON)
+
+// InstanceType is the shape of an EC2 instance type
+type InstanceType struct {
+	Name        string `json:"name"`
+	VCPUs       int64  `json:"vcpus"`
+	MemoryMiB   int64  `json:"memoryMiB"`
+	GPUs        int64  `json:"gpus,omitempty"`
+	GPUModel    string `json:"gpuModel,omitempty"`
+	NetworkMbps int64  `json:"networkMbps"`
+	// LocalStorageGiB is the size of the instance store volumes, 0 for EBS-only instance types
+	LocalStorageGiB int64 `json:"localStorageGiB,omitempty"`
+	MaxENIs         int   `json:"maxENIs"`
+	IPv4PerENI      int   `json:"ipv4PerENI"`
+	// MaxBranchENIs is the number of branch ENIs that can be attached to the trunk ENI
+	MaxBranchENIs int    `json:"maxBranchENIs"`
+	CPUModelName  string `json:"cpuModelName"`
+}
+
+func mustLoadCatalog(data []byte) map[string]*InstanceType {
+	var types []*InstanceType
+	if err := json.Unmarshal(data, &types); err != nil {
+		panic(fmt.Sprintf("invalid instance type catalog: %s", err))
+	}
+
+	c := make(map[string]*InstanceType, len(types))
+	for _, t := range types {
+		if _, ok := c[t.Name]; ok {
+			panic(fmt.Sprintf("instance type %s is in the catalog more than once", t.Name))
+		}
+		c[t.Name] = t
+	}
+	return c
+}
+
+// Get returns an instance type of the catalog
+func Get(name string) (*InstanceType, bool) {
+	t, ok := catalog[name]
+	if !ok {
+		return nil, false
+	}
+	copied := *t
+	return &copied, true
+}
+
+// All returns every instance type of the catalog, sorted by name
+func All() []*InstanceType {
+	types := make([]*InstanceType, 0, len(catalog))
+	for name := range catalog {
+		t, _ := Get(name)
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Name < types[j].Name
+	})
+	return types
+}
+
+// FamilyMembers returns the instance types of a family (such as "m5"), from the smallest to the largest
+func FamilyMembers(family string) []*InstanceType {
+	var members []*InstanceType
+	for _, t := range All() {
+		if t.Family() == family {
+			members = append(members, t)
+		}
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].compareSize(members[j]) < 0
+	})
+	return members
+}
+
+// Family returns the family of the instance type, such as "m5" for "m5.metal"
+func (t *InstanceType) Family() string {
+	return strings.SplitN(t.Name, ".", 2)[0]
+}
+
+// Size returns the size of the instance type, such as "metal" for "m5.metal"
+func (t *InstanceType) Size() string {
+	parts := strings.SplitN(t.Name, ".", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+// Generation returns the generation of the instance type, such as 5 for "m5d.metal", or 0 if the family
+// doesn't have one
+func (t *InstanceType) Generation() int {
+	generation := 0
+	for _, r := range strings.TrimLeftFunc(t.Family(), unicode.IsLetter) {
+		if !unicode.IsDigit(r) {
+			break
+		}
+		generation = generation*10 + int(r-'0')
+	}
+	return generation
+}
+
+// Compare returns -1, 0 or 1 if the instance type is smaller than, the same size as, or larger than the other
+// instance type. Only instance types of the same family can be compared.
+func (t *InstanceType) Compare(other *InstanceType) (int, error) {
+	if t.Family() != other.Family() {
+		return 0, fmt.Errorf("instance types %s and %s are not in the same family", t.Name, other.Name)
+	}
+	return t.compareSize(other), nil
+}
+
+func (t *InstanceType) compareSize(other *InstanceType) int {
+	switch {
+	case t.VCPUs != other.VCPUs:
+		return compareInt64(t.VCPUs, other.VCPUs)
+	case t.MemoryMiB != other.MemoryMiB:
+		return compareInt64(t.MemoryMiB, other.MemoryMiB)
+	default:
+		return compareInt64(t.GPUs, other.GPUs)
+	}
+}
+
+// MaxIPv4Addresses returns the number of IPv4 addresses that can be assigned across all the ENIs of the instance
+func (t *InstanceType) MaxIPv4Addresses() int {
+	return t.MaxENIs * t.IPv4PerENI
+}
+
+// ENIResourceSet returns the network capacity of the instance type
+func (t *InstanceType) ENIResourceSet() *node.ENIResourceSet {
+	return &node.ENIResourceSet{
+		ENIs:       t.MaxENIs,
+		BranchENIs: t.MaxBranchENIs,
+		IPs:        t.MaxIPv4Addresses(),
+	}
+}
+
+// Capacity returns the resources of the instance type, using canonical resource names and byte units.
+// Ephemeral storage is only included for instance types with instance store volumes.
+func (t *InstanceType) Capacity() corev1.ResourceList {
+	capacity := corev1.ResourceList{
+		resourceCommon.ResourceNameCpu:     *resource.NewQuantity(t.VCPUs, resource.DecimalSI),
+		resourceCommon.ResourceNameMemory:  *resource.NewQuantity(t.MemoryMiB*1024*1024, resource.BinarySI),
+		resourceCommon.ResourceNameGpu:     *resource.NewQuantity(t.GPUs, resource.DecimalSI),
+		resourceCommon.ResourceNameNetwork: *resource.NewQuantity(t.NetworkMbps*1000*1000, resource.DecimalSI),
+	}
+	if t.LocalStorageGiB > 0 {
+		capacity[resourceCommon.ResourceNameDisk] = *resource.NewQuantity(t.LocalStorageGiB*1024*1024*1024, resource.BinarySI)
+	}
+	return capacity
+}
+
+// ForNode returns the instance type of a node, from its instance type label or annotation
+func ForNode(n *corev1.Node) (*InstanceType, error) {
+	name, ok := n.Labels[node.LabelKeyInstanceType]
+	if !ok {
+		name, ok = n.Annotations[node.AnnotationKeyInstanceType]
+	}
+	if !ok {
+		return nil, fmt.Errorf("node %s has no instance type", n.Name)
+	}
+
+	t, ok := Get(name)
+	if !ok {
+		return nil, fmt.Errorf("node %s has instance type %s, which is not in the catalog", n.Name, name)
+	}
+	return t, nil
+}
+
+// ValidateNodeAllocatable returns an error if the allocatable resources of a node are inconsistent with its
+// instance type: more CPU, memory or network than the instance type has, too little memory (see
+// MinAllocatableMemoryFraction), or a different number of GPUs
+func ValidateNodeAllocatable(n *corev1.Node) error {
+	t, err := ForNode(n)
+	if err != nil {
+		return err
+	}
+
+	var result *multierror.Error
+	allocatable := resourceCommon.Add(n.Status.Allocatable)
+	capacity := t.Capacity()
+
+	for _, name := range []corev1.ResourceName{resourceCommon.ResourceNameCpu, resourceCommon.ResourceNameMemory, resourceCommon.ResourceNameNetwork} {
+		actual, ok := allocatable[name]
+		maximum := capacity[name]
+		if ok && actual.Cmp(maximum) > 0 {
+			result = multierror.Append(result, fmt.Errorf("node %s has %s %s allocatable, more than the %s of instance type %s",
+				n.Name, actual.String(), name, maximum.String(), t.Name))
+		}
+	}
+
+	memory := allocatable[resourceCommon.ResourceNameMemory]
+	minMemory := int64(float64(t.MemoryMiB*1024*1024) * MinAllocatableMemoryFraction)
+	if memory.Value() < minMemory {
+		result = multierror.Append(result, fmt.Errorf("node %s has %s memory allocatable, less than %.0f%% of the memory of instance type %s",
+			n.Name, memory.String(), MinAllocatableMemoryFraction*100, t.Name))
+	}
+
+	gpus := allocatable[resourceCommon.ResourceNameGpu]
+	if gpus.Value() != t.GPUs {
+		result = multierror.Append(result, fmt.Errorf("node %s has %d GPUs allocatable, but instance type %s has %d",
+			n.Name, gpus.Value(), t.Name, t.GPUs))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,123 @@
+package instancetype
+
+import (
+	"testing"
+
+	"github.com/Netflix/titus-kube-common/node"
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGet(t *testing.T) {
+	p3, ok := Get("p3.16xlarge")
+	assert.Assert(t, ok)
+	assert.Equal(t, p3.VCPUs, int64(64))
+	assert.Equal(t, p3.GPUs, int64(8))
+	assert.Equal(t, p3.Family(), "p3")
+	assert.Equal(t, p3.Size(), "16xlarge")
+	assert.Equal(t, p3.Generation(), 3)
+	assert.Equal(t, p3.MaxIPv4Addresses(), 240)
+	assert.DeepEqual(t, *p3.ENIResourceSet(), node.ENIResourceSet{ENIs: 8, BranchENIs: 120, IPs: 240})
+	assert.Equal(t, p3.ENIResourceSet().String(), "eni=8,branch=120,ip=240")
+
+	// The catalog can't be modified through the returned value
+	p3.GPUs = 0
+	p3, _ = Get("p3.16xlarge")
+	assert.Equal(t, p3.GPUs, int64(8))
+
+	_, ok = Get("t2.nano")
+	assert.Assert(t, !ok)
+}
+
+func TestCatalog(t *testing.T) {
+	for _, it := range All() {
+		assert.Assert(t, it.VCPUs > 0, it.Name)
+		assert.Assert(t, it.MemoryMiB > 0, it.Name)
+		assert.Assert(t, it.NetworkMbps > 0, it.Name)
+		assert.Assert(t, it.MaxENIs > 0 && it.IPv4PerENI > 0 && it.MaxBranchENIs > 0, it.Name)
+		assert.Assert(t, it.CPUModelName != "", it.Name)
+		assert.Assert(t, it.Generation() > 0, it.Name)
+		assert.Equal(t, it.GPUs > 0, it.GPUModel != "", it.Name)
+	}
+}
+
+func TestFamilyComparisons(t *testing.T) {
+	var names []string
+	for _, it := range FamilyMembers("m5") {
+		names = append(names, it.Name)
+	}
+	assert.DeepEqual(t, names, []string{"m5.large", "m5.xlarge", "m5.2xlarge", "m5.4xlarge", "m5.12xlarge", "m5.24xlarge", "m5.metal"})
+
+	large, _ := Get("m5.large")
+	metal, _ := Get("m5.metal")
+	cmp, err := large.Compare(metal)
+	assert.NilError(t, err)
+	assert.Equal(t, cmp, -1)
+
+	m5d, _ := Get("m5d.metal")
+	assert.Equal(t, m5d.Generation(), 5)
+	_, err = metal.Compare(m5d)
+	assert.ErrorContains(t, err, "instance types m5.metal and m5d.metal are not in the same family")
+}
+
+func TestCapacity(t *testing.T) {
+	g4dn, _ := Get("g4dn.metal")
+	capacity := g4dn.Capacity()
+	expected := map[corev1.ResourceName]string{
+		resourceCommon.ResourceNameCpu:     "96",
+		resourceCommon.ResourceNameMemory:  "384Gi",
+		resourceCommon.ResourceNameGpu:     "8",
+		resourceCommon.ResourceNameNetwork: "100G",
+		resourceCommon.ResourceNameDisk:    "1800Gi",
+	}
+	assert.Equal(t, len(capacity), len(expected))
+	for name, value := range expected {
+		quantity := capacity[name]
+		assert.Equal(t, quantity.Cmp(resource.MustParse(value)), 0, "%s: %s", name, quantity.String())
+	}
+
+	m5, _ := Get("m5.metal")
+	_, ok := m5.Capacity()[resourceCommon.ResourceNameDisk]
+	assert.Assert(t, !ok)
+}
+
+func buildNode(instanceType string, allocatable corev1.ResourceList) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "i-0123456789abcdef0",
+			Annotations: map[string]string{node.AnnotationKeyInstanceType: instanceType},
+		},
+		Status: corev1.NodeStatus{Allocatable: allocatable},
+	}
+}
+
+func TestValidateNodeAllocatable(t *testing.T) {
+	n := buildNode("p3.16xlarge", corev1.ResourceList{
+		resourceCommon.ResourceNameCpu:       resource.MustParse("62"),
+		resourceCommon.ResourceNameMemory:    resource.MustParse("460Gi"),
+		resourceCommon.ResourceNameNvidiaGpu: resource.MustParse("8"),
+		resourceCommon.ResourceNameNetwork:   resource.MustParse("25G"),
+	})
+	assert.NilError(t, ValidateNodeAllocatable(n))
+
+	n.Status.Allocatable = corev1.ResourceList{
+		resourceCommon.ResourceNameCpu:     resource.MustParse("96"),
+		resourceCommon.ResourceNameMemory:  resource.MustParse("128Gi"),
+		resourceCommon.ResourceNameGpu:     resource.MustParse("4"),
+		resourceCommon.ResourceNameNetwork: resource.MustParse("100G"),
+	}
+	err := ValidateNodeAllocatable(n)
+	assert.ErrorContains(t, err, "node i-0123456789abcdef0 has 96 cpu allocatable, more than the 64 of instance type p3.16xlarge")
+	assert.ErrorContains(t, err, "node i-0123456789abcdef0 has 100G titus/network allocatable, more than the 25G of instance type p3.16xlarge")
+	assert.ErrorContains(t, err, "node i-0123456789abcdef0 has 128Gi memory allocatable, less than 80% of the memory of instance type p3.16xlarge")
+	assert.ErrorContains(t, err, "node i-0123456789abcdef0 has 4 GPUs allocatable, but instance type p3.16xlarge has 8")
+
+	err = ValidateNodeAllocatable(buildNode("t2.nano", nil))
+	assert.ErrorContains(t, err, "node i-0123456789abcdef0 has instance type t2.nano, which is not in the catalog")
+
+	err = ValidateNodeAllocatable(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "i-0123456789abcdef0"}})
+	assert.ErrorContains(t, err, "node i-0123456789abcdef0 has no instance type")
+}
@@ -0,0 +1,120 @@
+// Package termination records termination annotations on Kubernetes objects, shared by the node and pod
+// termination helpers
+package termination
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// ErrAlreadyTerminating is returned when the object is already being deleted, or another caller already
+// recorded a termination reason on it
+var ErrAlreadyTerminating = errors.New("already being terminated")
+
+// Client gets and merge patches a single object
+type Client interface {
+	Get(ctx context.Context) (metav1.Object, error)
+	Patch(ctx context.Context, data []byte) (metav1.Object, error)
+}
+
+// Record sets the annotations on the object, as long as it isn't already terminating: it must not be
+// being deleted, nor have the reasonKey annotation. The patch carries the resource version of the last
+// object that was checked, so a concurrent modification makes it fail with a conflict. On conflicts,
+// the latest object is fetched and checked again before retrying. Record returns the patched object.
+func Record(ctx context.Context, c Client, obj metav1.Object, reasonKey string, annotations map[string]string) (metav1.Object, error) {
+	current := obj
+	var patched metav1.Object
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := checkNotTerminating(current, reasonKey); err != nil {
+			return err
+		}
+
+		data, err := annotationsPatch(current.GetResourceVersion(), stringValues(annotations))
+		if err != nil {
+			return err
+		}
+
+		patched, err = c.Patch(ctx, data)
+		if apierrors.IsConflict(err) {
+			latest, getErr := c.Get(ctx)
+			if getErr != nil {
+				return getErr
+			}
+			current = latest
+		}
+		return err
+	})
+	return patched, err
+}
+
+// Revert removes the annotations set by Record from the object it returned. If the object was modified since,
+// the annotations are only removed if they still have the values Record set, so that the termination reason of
+// another caller is kept. Objects that don't exist anymore are ignored.
+func Revert(ctx context.Context, c Client, recorded metav1.Object, annotations map[string]string) error {
+	current := recorded
+	removed := map[string]interface{}{}
+	for key := range annotations {
+		removed[key] = nil
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		for key, value := range annotations {
+			if current.GetAnnotations()[key] != value {
+				return nil
+			}
+		}
+
+		data, err := annotationsPatch(current.GetResourceVersion(), removed)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.Patch(ctx, data)
+		if apierrors.IsConflict(err) {
+			latest, getErr := c.Get(ctx)
+			if getErr != nil {
+				return getErr
+			}
+			current = latest
+		}
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func checkNotTerminating(obj metav1.Object, reasonKey string) error {
+	if obj.GetDeletionTimestamp() != nil {
+		return fmt.Errorf("%w: deletion was requested at %s", ErrAlreadyTerminating, obj.GetDeletionTimestamp())
+	}
+	if reason, ok := obj.GetAnnotations()[reasonKey]; ok {
+		return fmt.Errorf("%w: termination reason %q is already recorded", ErrAlreadyTerminating, reason)
+	}
+	return nil
+}
+
+func stringValues(annotations map[string]string) map[string]interface{} {
+	values := make(map[string]interface{}, len(annotations))
+	for key, value := range annotations {
+		values[key] = value
+	}
+	return values
+}
+
+// annotationsPatch builds a merge patch of the annotations, preconditioned on the resource version.
+// Annotations with a nil value are removed.
+func annotationsPatch(resourceVersion string, annotations map[string]interface{}) ([]byte, error) {
+	metadata := map[string]interface{}{"annotations": annotations}
+	if resourceVersion != "" {
+		metadata["resourceVersion"] = resourceVersion
+	}
+	return json.Marshal(map[string]interface{}{"metadata": metadata})
+}
@@ -0,0 +1,99 @@
+package mock
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// FleetGroup is a set of nodes sharing the same options, typically an ASG in a zone
+type FleetGroup struct {
+	// Options are the options of every node of the group. InstanceID must be empty.
+	Options NodeOptions
+	// Weight is the share of the fleet in this group, relative to the other groups
+	Weight int
+}
+
+// NewFleet generates count nodes, distributed across the groups proportionally to their weight. Shares that
+// don't divide evenly are rounded with the largest remainder method, so the fleet has exactly count nodes.
+// Nodes are returned group by group, and their instance IDs are drawn from rnd: the same seed generates the
+// same fleet.
+func NewFleet(rnd *rand.Rand, count int, groups []FleetGroup) ([]*corev1.Node, error) {
+	if rnd == nil {
+		return nil, fmt.Errorf("fleet has no random source to generate instance IDs")
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("fleet size is negative: %d", count)
+	}
+	if count > 0 && len(groups) == 0 {
+		return nil, fmt.Errorf("fleet has no group")
+	}
+
+	totalWeight := 0
+	for i, g := range groups {
+		if g.Weight < 0 {
+			return nil, fmt.Errorf("fleet group %d has a negative weight: %d", i, g.Weight)
+		}
+		if g.Options.InstanceID != "" {
+			return nil, fmt.Errorf("fleet group %d sets an instance ID", i)
+		}
+		totalWeight += g.Weight
+	}
+	if count > 0 && totalWeight == 0 {
+		return nil, fmt.Errorf("fleet groups have no weight")
+	}
+
+	nodes := make([]*corev1.Node, 0, count)
+	instanceIDs := map[string]bool{}
+	for i, size := range distribute(count, groups, totalWeight) {
+		for j := 0; j < size; j++ {
+			opts := groups[i].Options
+			opts.InstanceID = randomInstanceID(rnd)
+			for instanceIDs[opts.InstanceID] {
+				opts.InstanceID = randomInstanceID(rnd)
+			}
+			instanceIDs[opts.InstanceID] = true
+
+			n, err := NewNode(opts)
+			if err != nil {
+				return nil, fmt.Errorf("could not generate nodes for fleet group %d: %w", i, err)
+			}
+			nodes = append(nodes, n)
+		}
+	}
+
+	return nodes, nil
+}
+
+// distribute splits count across the groups using the largest remainder method. Ties are broken in favor of
+// the first groups.
+func distribute(count int, groups []FleetGroup, totalWeight int) []int {
+	sizes := make([]int, len(groups))
+	if count == 0 {
+		return sizes
+	}
+
+	remainders := make([]int, len(groups))
+	assigned := 0
+	for i, g := range groups {
+		sizes[i] = count * g.Weight / totalWeight
+		remainders[i] = count * g.Weight % totalWeight
+		assigned += sizes[i]
+	}
+
+	order := make([]int, len(groups))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for i := 0; assigned < count; i++ {
+		sizes[order[i]]++
+		assigned++
+	}
+
+	return sizes
+}
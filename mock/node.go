@@ -0,0 +1,158 @@
+// Package mock generates fake nodes for the mock-nodes resource pool, to load test schedulers and controllers
+// without real instances.
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/Netflix/titus-kube-common/instancetype"
+	"github.com/Netflix/titus-kube-common/node"
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	"github.com/Netflix/titus-kube-common/resourcepool"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultInstanceType is the instance type of generated nodes when none is specified
+	DefaultInstanceType = "m5.metal"
+	// DefaultRegion is the region of generated nodes when none is specified
+	DefaultRegion = "us-east-1"
+	// DefaultASG is the ASG of generated nodes when none is specified
+	DefaultASG = "titusagent-mock-v000"
+
+	// maxPods is the pod capacity of generated nodes
+	maxPods = 256
+	// rootVolumeSize is the ephemeral storage of generated nodes of instance types without instance store volumes
+	rootVolumeSize = "1000Gi"
+)
+
+// NodeOptions describes the node to generate. All fields are optional.
+type NodeOptions struct {
+	// InstanceID is the instance ID, and name, of the node. It is drawn from Rand if empty.
+	InstanceID string
+	// Rand generates the instance ID when InstanceID is empty, so that generated nodes are reproducible from
+	// the seed of Rand. One of InstanceID and Rand must be set.
+	Rand *rand.Rand
+	// InstanceType defaults to DefaultInstanceType
+	InstanceType string
+	// ASG defaults to DefaultASG
+	ASG string
+	// Region defaults to DefaultRegion, or to the region of Zone if set
+	Region string
+	// Zone defaults to the "a" zone of the region
+	Zone string
+	// Backend is the backend label of the node, and the value of its backend taint. It defaults to
+	// node.LabelValueBackendMock. Kubelet nodes have no backend taint.
+	Backend string
+	// Labels are added to the generated labels, and override them
+	Labels map[string]string
+	// Taints are added to the generated taints
+	Taints []corev1.Taint
+}
+
+// NewNode returns a ready node of the mock-nodes resource pool
+func NewNode(opts NodeOptions) (*corev1.Node, error) {
+	if opts.InstanceType == "" {
+		opts.InstanceType = DefaultInstanceType
+	}
+	itype, ok := instancetype.Get(opts.InstanceType)
+	if !ok {
+		return nil, fmt.Errorf("unknown instance type: %s", opts.InstanceType)
+	}
+
+	if opts.InstanceID == "" {
+		if opts.Rand == nil {
+			return nil, errors.New("node has neither an instance ID nor a random source to generate one")
+		}
+		opts.InstanceID = randomInstanceID(opts.Rand)
+	}
+	if opts.ASG == "" {
+		opts.ASG = DefaultASG
+	}
+	if opts.Backend == "" {
+		opts.Backend = node.LabelValueBackendMock
+	}
+	if opts.Region == "" {
+		opts.Region = regionOfZone(opts.Zone)
+	}
+	if opts.Zone == "" {
+		opts.Zone = opts.Region + "a"
+	}
+	if !node.ZoneInRegion(opts.Zone, opts.Region) {
+		return nil, fmt.Errorf("zone %s is not in region %s", opts.Zone, opts.Region)
+	}
+
+	labels := map[string]string{
+		corev1.LabelHostname:       opts.InstanceID,
+		corev1.LabelTopologyRegion: opts.Region,
+		corev1.LabelTopologyZone:   opts.Zone,
+		node.LabelKeyASG:           opts.ASG,
+		node.LabelKeyBackend:       opts.Backend,
+		node.LabelKeyInstanceID:    opts.InstanceID,
+		node.LabelKeyInstanceType:  opts.InstanceType,
+		node.LabelKeyResourcePool:  resourcepool.ResourcePoolMockNodes,
+	}
+	for key, value := range opts.Labels {
+		labels[key] = value
+	}
+
+	var taints []corev1.Taint
+	if opts.Backend != node.LabelValueBackendKubelet {
+		taints = append(taints, corev1.Taint{Key: node.TaintKeyBackend, Value: opts.Backend, Effect: corev1.TaintEffectNoSchedule})
+	}
+	if itype.GPUs > 0 {
+		taints = append(taints, corev1.Taint{Key: node.TaintKeyGPUNode, Value: "true", Effect: corev1.TaintEffectNoSchedule})
+	}
+	taints = append(taints, opts.Taints...)
+
+	capacity := itype.Capacity()
+	if _, ok := capacity[resourceCommon.ResourceNameDisk]; !ok {
+		capacity[resourceCommon.ResourceNameDisk] = resource.MustParse(rootVolumeSize)
+	}
+	capacity[resourceCommon.ResourceNamePods] = *resource.NewQuantity(maxPods, resource.DecimalSI)
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   opts.InstanceID,
+			Labels: labels,
+			Annotations: map[string]string{
+				node.AnnotationKeyASG:          opts.ASG,
+				node.AnnotationKeyInstanceID:   opts.InstanceID,
+				node.AnnotationKeyInstanceType: opts.InstanceType,
+				node.AnnotationKeyRegion:       opts.Region,
+				node.AnnotationKeyZone:         opts.Zone,
+			},
+		},
+		Spec: corev1.NodeSpec{
+			ProviderID: fmt.Sprintf("aws:///%s/%s", opts.Zone, opts.InstanceID),
+			Taints:     taints,
+		},
+		Status: corev1.NodeStatus{
+			Capacity:    capacity,
+			Allocatable: capacity.DeepCopy(),
+			Conditions: []corev1.NodeCondition{
+				{
+					Type:   corev1.NodeReady,
+					Status: corev1.ConditionTrue,
+					Reason: "MockNodeReady",
+				},
+			},
+		},
+	}, nil
+}
+
+// regionOfZone returns the region of an AWS availability zone, or DefaultRegion if the zone is empty
+func regionOfZone(zone string) string {
+	if zone == "" {
+		return DefaultRegion
+	}
+	return zone[:len(zone)-1]
+}
+
+// randomInstanceID returns an instance ID in the EC2 format: "i-" followed by 17 hex digits
+func randomInstanceID(rnd *rand.Rand) string {
+	return fmt.Sprintf("i-%x%016x", rnd.Intn(16), rnd.Uint64())
+}
@@ -0,0 +1,141 @@
+package mock
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/Netflix/titus-kube-common/instancetype"
+	"github.com/Netflix/titus-kube-common/node"
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	"github.com/Netflix/titus-kube-common/resourcepool"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestNewNode(t *testing.T) {
+	opts := NodeOptions{
+		InstanceType: "p3.16xlarge",
+		ASG:          "titusagent-gpu-v001",
+		Zone:         "us-west-2b",
+		Rand:         rand.New(rand.NewSource(1)),
+	}
+	n, err := NewNode(opts)
+	assert.NilError(t, err)
+
+	// The instance ID only depends on the seed
+	opts.Rand = rand.New(rand.NewSource(1))
+	same, err := NewNode(opts)
+	assert.NilError(t, err)
+	assert.Equal(t, same.Name, n.Name)
+
+	assert.Assert(t, node.IsMockNode(n))
+	assert.Equal(t, n.Labels[node.LabelKeyResourcePool], resourcepool.ResourcePoolMockNodes)
+	assert.Equal(t, n.Labels[node.LabelKeyInstanceType], "p3.16xlarge")
+	assert.Equal(t, n.Labels[corev1.LabelTopologyRegion], "us-west-2")
+	assert.Equal(t, len(n.Name), 19)
+
+	conf, err := node.NodeToConfig(n)
+	assert.NilError(t, err)
+	assert.Equal(t, *conf.ASG, "titusagent-gpu-v001")
+	assert.Equal(t, *conf.InstanceID, n.Name)
+	assert.Equal(t, *conf.Zone, "us-west-2b")
+	assert.Equal(t, len(node.LabelAnnotationMismatches(n)), 0)
+
+	assert.DeepEqual(t, n.Spec.Taints, []corev1.Taint{
+		{Key: node.TaintKeyBackend, Value: node.LabelValueBackendMock, Effect: corev1.TaintEffectNoSchedule},
+		{Key: node.TaintKeyGPUNode, Value: "true", Effect: corev1.TaintEffectNoSchedule},
+	})
+
+	gpu := n.Status.Allocatable[resourceCommon.ResourceNameGpu]
+	assert.Equal(t, gpu.Cmp(resource.MustParse("8")), 0)
+	memory := n.Status.Allocatable[resourceCommon.ResourceNameMemory]
+	assert.Equal(t, memory.Cmp(resource.MustParse("488Gi")), 0)
+	assert.NilError(t, instancetype.ValidateNodeAllocatable(n))
+
+	// Kubelet nodes don't have the backend taint
+	opts.Backend = node.LabelValueBackendKubelet
+	kubelet, err := NewNode(opts)
+	assert.NilError(t, err)
+	assert.Equal(t, kubelet.Labels[node.LabelKeyBackend], node.LabelValueBackendKubelet)
+	assert.DeepEqual(t, kubelet.Spec.Taints, []corev1.Taint{
+		{Key: node.TaintKeyGPUNode, Value: "true", Effect: corev1.TaintEffectNoSchedule},
+	})
+}
+
+func TestNewNodeDefaults(t *testing.T) {
+	n, err := NewNode(NodeOptions{
+		InstanceID: "i-0123456789abcdef0",
+		Labels:     map[string]string{"example.com/team": "scheduling"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, n.Name, "i-0123456789abcdef0")
+	assert.Equal(t, n.Labels[node.LabelKeyInstanceType], DefaultInstanceType)
+	assert.Equal(t, n.Labels[node.LabelKeyASG], DefaultASG)
+	assert.Equal(t, n.Labels[corev1.LabelTopologyZone], "us-east-1a")
+	assert.Equal(t, n.Labels["example.com/team"], "scheduling")
+	assert.Equal(t, len(n.Spec.Taints), 1)
+}
+
+func TestNewNodeInvalid(t *testing.T) {
+	_, err := NewNode(NodeOptions{})
+	assert.ErrorContains(t, err, "node has neither an instance ID nor a random source to generate one")
+
+	_, err = NewNode(NodeOptions{InstanceID: "i-0123456789abcdef0", InstanceType: "t2.nano"})
+	assert.ErrorContains(t, err, "unknown instance type: t2.nano")
+
+	_, err = NewNode(NodeOptions{InstanceID: "i-0123456789abcdef0", Region: "us-east-1", Zone: "eu-west-1a"})
+	assert.ErrorContains(t, err, "zone eu-west-1a is not in region us-east-1")
+
+	_, err = NewNode(NodeOptions{InstanceID: "i-0123456789abcdef0", Region: "us-east-1", Zone: "us-east-10a"})
+	assert.ErrorContains(t, err, "zone us-east-10a is not in region us-east-1")
+}
+
+func TestNewFleet(t *testing.T) {
+	groups := []FleetGroup{
+		{Options: NodeOptions{ASG: "titusagent-v001", Zone: "us-east-1a"}, Weight: 1},
+		{Options: NodeOptions{ASG: "titusagent-v001", Zone: "us-east-1b"}, Weight: 1},
+		{Options: NodeOptions{ASG: "titusagent-v001", Zone: "us-east-1c"}, Weight: 1},
+		{Options: NodeOptions{ASG: "titusagent-r5-v001", InstanceType: "r5.metal", Zone: "us-east-1a"}, Weight: 2},
+	}
+
+	nodes, err := NewFleet(rand.New(rand.NewSource(1)), 11, groups)
+	assert.NilError(t, err)
+	assert.Equal(t, len(nodes), 11)
+
+	same, err := NewFleet(rand.New(rand.NewSource(1)), 11, groups)
+	assert.NilError(t, err)
+	for i := range nodes {
+		assert.Equal(t, same[i].Name, nodes[i].Name)
+	}
+
+	zones := map[string]int{}
+	asgs := map[string]int{}
+	names := map[string]bool{}
+	for _, n := range nodes {
+		zones[n.Labels[corev1.LabelTopologyZone]]++
+		asgs[n.Labels[node.LabelKeyASG]]++
+		names[n.Name] = true
+	}
+	assert.Equal(t, len(names), 11)
+	// 11 * 1/5 = 2.2 and 11 * 2/5 = 4.4: the remaining node goes to the group with the largest remainder
+	assert.DeepEqual(t, asgs, map[string]int{"titusagent-v001": 6, "titusagent-r5-v001": 5})
+	assert.DeepEqual(t, zones, map[string]int{"us-east-1a": 7, "us-east-1b": 2, "us-east-1c": 2})
+}
+
+func TestNewFleetInvalid(t *testing.T) {
+	_, err := NewFleet(nil, 10, []FleetGroup{{Weight: 1}})
+	assert.ErrorContains(t, err, "fleet has no random source to generate instance IDs")
+
+	_, err = NewFleet(rand.New(rand.NewSource(1)), 10, nil)
+	assert.ErrorContains(t, err, "fleet has no group")
+
+	_, err = NewFleet(rand.New(rand.NewSource(1)), 10, []FleetGroup{{Weight: 0}})
+	assert.ErrorContains(t, err, "fleet groups have no weight")
+
+	_, err = NewFleet(rand.New(rand.NewSource(1)), 10, []FleetGroup{{Options: NodeOptions{InstanceID: "i-0123456789abcdef0"}, Weight: 1}})
+	assert.ErrorContains(t, err, "fleet group 0 sets an instance ID")
+
+	_, err = NewFleet(rand.New(rand.NewSource(1)), 10, []FleetGroup{{Options: NodeOptions{InstanceType: "t2.nano"}, Weight: 1}})
+	assert.ErrorContains(t, err, "could not generate nodes for fleet group 0: unknown instance type: t2.nano")
+}
@@ -0,0 +1,202 @@
+package node
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeConfig contains configuration parameters parsed out from the annotations and labels of a node.
+// All fields are pointers, to differentiate between a field being unset and the empty value.
+type NodeConfig struct {
+	Account      *string
+	AccountID    *string
+	AMI          *string
+	ASG          *string
+	Backend      *string
+	Cluster      *string
+	CPUModelName *string
+	InstanceID   *string
+	InstanceType *string
+	Region       *string
+	ResourcePool *string
+	Stack        *string
+	Zone         *string
+}
+
+// LabelAnnotationMismatch describes a value that is stored both as a label and as an annotation of a node,
+// where both copies disagree
+type LabelAnnotationMismatch struct {
+	LabelKey        string
+	LabelValue      string
+	AnnotationKey   string
+	AnnotationValue string
+}
+
+func (m LabelAnnotationMismatch) String() string {
+	return fmt.Sprintf("label %s=%s does not match annotation %s=%s", m.LabelKey, m.LabelValue, m.AnnotationKey, m.AnnotationValue)
+}
+
+// labelAnnotationPairs lists the values stored both as an annotation and a label. The annotation is the
+// authoritative copy, the label is used as a fallback when the annotation is missing.
+var labelAnnotationPairs = []struct {
+	annotationKey string
+	labelKey      string
+}{
+	{annotationKey: AnnotationKeyASG, labelKey: LabelKeyASG},
+	{annotationKey: AnnotationKeyInstanceID, labelKey: LabelKeyInstanceID},
+	{annotationKey: AnnotationKeyInstanceType, labelKey: LabelKeyInstanceType},
+	{annotationKey: AnnotationKeyRegion, labelKey: corev1.LabelTopologyRegion},
+	{annotationKey: AnnotationKeyZone, labelKey: corev1.LabelTopologyZone},
+}
+
+// NodeToConfig pulls out values from a node and turns them into a NodeConfig
+func NodeToConfig(node *corev1.Node) (*NodeConfig, error) {
+	nConf := &NodeConfig{}
+	annotations := node.GetAnnotations()
+	labels := node.GetLabels()
+
+	stringAnnotations := []struct {
+		key   string
+		field **string
+	}{
+		{
+			key:   AnnotationKeyAccount,
+			field: &nConf.Account,
+		},
+		{
+			key:   AnnotationKeyAccountID,
+			field: &nConf.AccountID,
+		},
+		{
+			key:   AnnotationKeyAMI,
+			field: &nConf.AMI,
+		},
+		{
+			key:   AnnotationKeyASG,
+			field: &nConf.ASG,
+		},
+		{
+			key:   AnnotationKeyCluster,
+			field: &nConf.Cluster,
+		},
+		{
+			key:   AnnotationKeyInstanceID,
+			field: &nConf.InstanceID,
+		},
+		{
+			key:   AnnotationKeyInstanceType,
+			field: &nConf.InstanceType,
+		},
+		{
+			key:   AnnotationKeyRegion,
+			field: &nConf.Region,
+		},
+		{
+			key:   AnnotationKeyStack,
+			field: &nConf.Stack,
+		},
+		{
+			key:   AnnotationKeyZone,
+			field: &nConf.Zone,
+		},
+	}
+
+	stringLabels := []struct {
+		key   string
+		field **string
+	}{
+		{
+			key:   LabelKeyBackend,
+			field: &nConf.Backend,
+		},
+		{
+			key:   LabelKeyCpuModelName,
+			field: &nConf.CPUModelName,
+		},
+		{
+			key:   LabelKeyResourcePool,
+			field: &nConf.ResourcePool,
+		},
+	}
+
+	labelFallbacks := map[string]string{}
+	for _, pair := range labelAnnotationPairs {
+		labelFallbacks[pair.annotationKey] = pair.labelKey
+	}
+
+	for _, an := range stringAnnotations {
+		val, ok := annotations[an.key]
+		if !ok {
+			if labelKey, hasFallback := labelFallbacks[an.key]; hasFallback {
+				val, ok = labels[labelKey]
+			}
+		}
+		if ok {
+			*an.field = &val
+		}
+	}
+
+	for _, l := range stringLabels {
+		val, ok := labels[l.key]
+		if ok {
+			*l.field = &val
+		}
+	}
+
+	var err *multierror.Error
+
+	if nConf.Backend != nil {
+		switch *nConf.Backend {
+		case LabelValueBackendKubelet, LabelValueBackendMock, LabelValueBackendVirtualKubelet:
+		default:
+			err = multierror.Append(err, fmt.Errorf("%s label is not a valid backend: %s", LabelKeyBackend, *nConf.Backend))
+		}
+	}
+
+	if nConf.Region != nil && nConf.Zone != nil {
+		if !ZoneInRegion(*nConf.Zone, *nConf.Region) {
+			err = multierror.Append(err, fmt.Errorf("zone %s is not in region %s", *nConf.Zone, *nConf.Region))
+		}
+	}
+
+	return nConf, err.ErrorOrNil()
+}
+
+// ZoneInRegion returns whether an availability zone is in a region. Zones are named after their region
+// followed by a single lowercase letter, such as us-east-1a.
+func ZoneInRegion(zone, region string) bool {
+	if len(zone) != len(region)+1 || !strings.HasPrefix(zone, region) {
+		return false
+	}
+	letter := zone[len(zone)-1]
+	return letter >= 'a' && letter <= 'z'
+}
+
+// LabelAnnotationMismatches returns the values stored both as a label and an annotation of the node
+// for which both copies disagree. Values that are only set on one side are not reported.
+func LabelAnnotationMismatches(node *corev1.Node) []LabelAnnotationMismatch {
+	var mismatches []LabelAnnotationMismatch
+	for _, pair := range labelAnnotationPairs {
+		annotationVal, ok := node.GetAnnotations()[pair.annotationKey]
+		if !ok {
+			continue
+		}
+		labelVal, ok := node.GetLabels()[pair.labelKey]
+		if !ok {
+			continue
+		}
+		if annotationVal != labelVal {
+			mismatches = append(mismatches, LabelAnnotationMismatch{
+				LabelKey:        pair.labelKey,
+				LabelValue:      labelVal,
+				AnnotationKey:   pair.annotationKey,
+				AnnotationValue: annotationVal,
+			})
+		}
+	}
+
+	return mismatches
+}
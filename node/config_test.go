@@ -0,0 +1,150 @@
+package node
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ptr "k8s.io/utils/pointer"
+)
+
+func buildNode(annotations, labels map[string]string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "i-0123456789abcdef0",
+			Annotations: annotations,
+			Labels:      labels,
+		},
+	}
+}
+
+func TestNodeToConfig(t *testing.T) {
+	node := buildNode(map[string]string{
+		AnnotationKeyAccount:      "titusprod",
+		AnnotationKeyAccountID:    "123456789012",
+		AnnotationKeyAMI:          "ami-123",
+		AnnotationKeyASG:          "titusagent-v001",
+		AnnotationKeyCluster:      "titusagent",
+		AnnotationKeyInstanceID:   "i-0123456789abcdef0",
+		AnnotationKeyInstanceType: "m5.metal",
+		AnnotationKeyRegion:       "us-east-1",
+		AnnotationKeyStack:        "main",
+		AnnotationKeyZone:         "us-east-1a",
+	}, map[string]string{
+		LabelKeyBackend:      LabelValueBackendKubelet,
+		LabelKeyCpuModelName: "Intel(R) Xeon(R) Platinum 8175M CPU @ 2.50GHz",
+		LabelKeyResourcePool: "elastic",
+	})
+
+	conf, err := NodeToConfig(node)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, *conf, NodeConfig{
+		Account:      ptr.StringPtr("titusprod"),
+		AccountID:    ptr.StringPtr("123456789012"),
+		AMI:          ptr.StringPtr("ami-123"),
+		ASG:          ptr.StringPtr("titusagent-v001"),
+		Backend:      ptr.StringPtr(LabelValueBackendKubelet),
+		Cluster:      ptr.StringPtr("titusagent"),
+		CPUModelName: ptr.StringPtr("Intel(R) Xeon(R) Platinum 8175M CPU @ 2.50GHz"),
+		InstanceID:   ptr.StringPtr("i-0123456789abcdef0"),
+		InstanceType: ptr.StringPtr("m5.metal"),
+		Region:       ptr.StringPtr("us-east-1"),
+		ResourcePool: ptr.StringPtr("elastic"),
+		Stack:        ptr.StringPtr("main"),
+		Zone:         ptr.StringPtr("us-east-1a"),
+	})
+}
+
+func TestNodeToConfigLabelFallback(t *testing.T) {
+	node := buildNode(map[string]string{
+		AnnotationKeyASG: "titusagent-v002",
+	}, map[string]string{
+		LabelKeyASG:                "titusagent-v001",
+		LabelKeyInstanceType:       "r5.metal",
+		corev1.LabelTopologyRegion: "us-west-2",
+		corev1.LabelTopologyZone:   "us-west-2b",
+	})
+
+	conf, err := NodeToConfig(node)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, *conf, NodeConfig{
+		ASG:          ptr.StringPtr("titusagent-v002"),
+		InstanceType: ptr.StringPtr("r5.metal"),
+		Region:       ptr.StringPtr("us-west-2"),
+		Zone:         ptr.StringPtr("us-west-2b"),
+	})
+}
+
+func TestNodeToConfigInvalid(t *testing.T) {
+	badNodes := []struct {
+		annotations map[string]string
+		labels      map[string]string
+		errMatch    string
+	}{
+		{
+			annotations: map[string]string{
+				AnnotationKeyRegion: "us-east-1",
+				AnnotationKeyZone:   "us-west-2a",
+			},
+			errMatch: "zone us-west-2a is not in region us-east-1",
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyRegion: "us-east-1",
+				AnnotationKeyZone:   "us-east-1",
+			},
+			errMatch: "zone us-east-1 is not in region us-east-1",
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyRegion: "us-east-1",
+				AnnotationKeyZone:   "us-east-10a",
+			},
+			errMatch: "zone us-east-10a is not in region us-east-1",
+		},
+		{
+			labels: map[string]string{
+				LabelKeyBackend: "docker",
+			},
+			errMatch: "node.titus.netflix.com/backend label is not a valid backend: docker",
+		},
+	}
+
+	for _, n := range badNodes {
+		_, err := NodeToConfig(buildNode(n.annotations, n.labels))
+		assert.ErrorContains(t, err, n.errMatch)
+	}
+}
+
+func TestLabelAnnotationMismatches(t *testing.T) {
+	node := buildNode(map[string]string{
+		AnnotationKeyASG:          "titusagent-v002",
+		AnnotationKeyInstanceID:   "i-0123456789abcdef0",
+		AnnotationKeyInstanceType: "m5.metal",
+		AnnotationKeyZone:         "us-east-1a",
+	}, map[string]string{
+		LabelKeyASG:              "titusagent-v001",
+		LabelKeyInstanceID:       "i-0123456789abcdef0",
+		LabelKeyInstanceType:     "m5.metal",
+		corev1.LabelTopologyZone: "us-east-1c",
+	})
+
+	mismatches := LabelAnnotationMismatches(node)
+	assert.DeepEqual(t, mismatches, []LabelAnnotationMismatch{
+		{
+			LabelKey:        LabelKeyASG,
+			LabelValue:      "titusagent-v001",
+			AnnotationKey:   AnnotationKeyASG,
+			AnnotationValue: "titusagent-v002",
+		},
+		{
+			LabelKey:        corev1.LabelTopologyZone,
+			LabelValue:      "us-east-1c",
+			AnnotationKey:   AnnotationKeyZone,
+			AnnotationValue: "us-east-1a",
+		},
+	})
+	assert.Equal(t, mismatches[0].String(),
+		"label node.titus.netflix.com/asg=titusagent-v001 does not match annotation node.titus.netflix.com/asg=titusagent-v002")
+}
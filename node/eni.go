@@ -0,0 +1,87 @@
+package node
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	eniResourceSetKeyENIs       = "eni"
+	eniResourceSetKeyBranchENIs = "branch"
+	eniResourceSetKeyIPs        = "ip"
+)
+
+// ENIResourceSet is the network capacity of a node: the ENIs, branch ENIs and IP addresses that can be assigned
+// to its pods. Nodes advertise it in AnnotationKeyENIResourceSet, as a comma separated list of key=value pairs,
+// for example "eni=15,branch=120,ip=750" for an m5.metal. For nodes without the annotation, the capacity of
+// their instance type can be used instead, see instancetype.InstanceType.ENIResourceSet.
+type ENIResourceSet struct {
+	// ENIs is the number of ENIs that can be attached to the node, including the trunk ENI
+	ENIs int
+	// BranchENIs is the number of branch ENIs that can be associated with the trunk ENI
+	BranchENIs int
+	// IPs is the number of IP addresses that can be assigned to pods. Allocation indexes range from 0 to IPs-1.
+	IPs int
+}
+
+// String returns the annotation value of the resource set
+func (s ENIResourceSet) String() string {
+	return fmt.Sprintf("%s=%d,%s=%d,%s=%d",
+		eniResourceSetKeyENIs, s.ENIs, eniResourceSetKeyBranchENIs, s.BranchENIs, eniResourceSetKeyIPs, s.IPs)
+}
+
+// ParseENIResourceSet parses the value of AnnotationKeyENIResourceSet. All keys are required.
+func ParseENIResourceSet(value string) (*ENIResourceSet, error) {
+	set := &ENIResourceSet{}
+	fields := map[string]*int{
+		eniResourceSetKeyENIs:       &set.ENIs,
+		eniResourceSetKeyBranchENIs: &set.BranchENIs,
+		eniResourceSetKeyIPs:        &set.IPs,
+	}
+	seen := map[string]bool{}
+
+	for _, pair := range strings.Split(value, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("ENI resource set %q has an entry that isn't a key=value pair: %q", value, pair)
+		}
+		key := strings.TrimSpace(kv[0])
+		field, ok := fields[key]
+		if !ok {
+			return nil, fmt.Errorf("ENI resource set %q has an unknown key: %s", value, key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("ENI resource set %q has key %s more than once", value, key)
+		}
+		seen[key] = true
+
+		n, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("ENI resource set %q has an invalid %s value: %w", value, key, err)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("ENI resource set %q has a negative %s value: %d", value, key, n)
+		}
+		*field = n
+	}
+
+	for _, key := range []string{eniResourceSetKeyENIs, eniResourceSetKeyBranchENIs, eniResourceSetKeyIPs} {
+		if !seen[key] {
+			return nil, fmt.Errorf("ENI resource set %q is missing key %s", value, key)
+		}
+	}
+
+	return set, nil
+}
+
+// GetENIResourceSet returns the ENI resource set of a node, or nil if the node doesn't have one
+func GetENIResourceSet(node *corev1.Node) (*ENIResourceSet, error) {
+	value, ok := node.Annotations[AnnotationKeyENIResourceSet]
+	if !ok {
+		return nil, nil
+	}
+	return ParseENIResourceSet(value)
+}
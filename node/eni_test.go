@@ -0,0 +1,63 @@
+package node
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestParseENIResourceSet(t *testing.T) {
+	set, err := ParseENIResourceSet("eni=15, branch=120, ip=750")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, *set, ENIResourceSet{ENIs: 15, BranchENIs: 120, IPs: 750})
+	assert.Equal(t, set.String(), "eni=15,branch=120,ip=750")
+
+	node := buildNode(map[string]string{AnnotationKeyENIResourceSet: set.String()}, nil)
+	parsed, err := GetENIResourceSet(node)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, parsed, set)
+
+	parsed, err = GetENIResourceSet(buildNode(nil, nil))
+	assert.NilError(t, err)
+	assert.Assert(t, parsed == nil)
+}
+
+func TestParseENIResourceSetInstanceTypes(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected ENIResourceSet
+	}{
+		// m5.metal and r5.metal
+		{value: "eni=15,branch=120,ip=750", expected: ENIResourceSet{ENIs: 15, BranchENIs: 120, IPs: 750}},
+		// p3.16xlarge
+		{value: "eni=8,branch=120,ip=240", expected: ENIResourceSet{ENIs: 8, BranchENIs: 120, IPs: 240}},
+		// m5.large
+		{value: "eni=3,branch=10,ip=30", expected: ENIResourceSet{ENIs: 3, BranchENIs: 10, IPs: 30}},
+	}
+
+	for _, tt := range tests {
+		set, err := ParseENIResourceSet(tt.value)
+		assert.NilError(t, err, tt.value)
+		assert.DeepEqual(t, *set, tt.expected)
+		assert.Equal(t, set.String(), tt.value)
+	}
+}
+
+func TestParseENIResourceSetInvalid(t *testing.T) {
+	tests := []struct {
+		value    string
+		errMatch string
+	}{
+		{value: "eni=15,branch=120", errMatch: `ENI resource set "eni=15,branch=120" is missing key ip`},
+		{value: "eni=15,branch=120,ip", errMatch: `has an entry that isn't a key=value pair: "ip"`},
+		{value: "eni=15,branch=120,ip=750,trunk=1", errMatch: "has an unknown key: trunk"},
+		{value: "eni=15,eni=16,branch=120,ip=750", errMatch: "has key eni more than once"},
+		{value: "eni=many,branch=120,ip=750", errMatch: "has an invalid eni value: strconv.Atoi"},
+		{value: "eni=15,branch=-1,ip=750", errMatch: "has a negative branch value: -1"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseENIResourceSet(tt.value)
+		assert.ErrorContains(t, err, tt.errMatch)
+	}
+}
@@ -21,6 +21,11 @@ const (
 	LabelValueBackendMock           = "mock"
 	LabelValueBackendVirtualKubelet = "VirtualKubelet"
 	LabelValueBackendKubelet        = "kubelet"
+
+	// Values of LabelKeyResourcePool. The resourcepool package exposes them as ResourcePool* constants.
+	LabelValueResourcePoolElastic   = "elastic"
+	LabelValueResourcePoolReserved  = "reserved"
+	LabelValueResourcePoolMockNodes = "mock-nodes"
 )
 
 func IsMockNode(node *corev1.Node) bool {
@@ -0,0 +1,245 @@
+package node
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LifecycleState is the lifecycle stage of a node, derived from its lifecycle labels and taints
+type LifecycleState string
+
+const (
+	// LifecycleStateInitializing nodes are still being set up and don't accept pods
+	LifecycleStateInitializing LifecycleState = "Initializing"
+	// LifecycleStateActive nodes accept pods
+	LifecycleStateActive LifecycleState = "Active"
+	// LifecycleStateDecommissioning nodes don't accept new pods, but keep running their existing pods
+	LifecycleStateDecommissioning LifecycleState = "Decommissioning"
+	// LifecycleStateEvacuating nodes are decommissioned and their pods are being evicted
+	LifecycleStateEvacuating LifecycleState = "Evacuating"
+	// LifecycleStateScalingDown nodes have been picked by the scaler for removal
+	LifecycleStateScalingDown LifecycleState = "ScalingDown"
+	// LifecycleStateTerminating nodes are being terminated. This is a final state.
+	LifecycleStateTerminating LifecycleState = "Terminating"
+)
+
+type lifecycleMarkers struct {
+	labels []string
+	taints []corev1.Taint
+}
+
+var (
+	lifecycleLabelKeys = []string{LabelKeyDecommissioning, LabelKeyRemovable, LabelKeyTerminating}
+	lifecycleTaintKeys = []string{TaintKeyInit, TaintKeyNodeDecommissioning, TaintKeyNodeEvacuate, TaintKeyNodeScalingDown}
+
+	taintInit            = corev1.Taint{Key: TaintKeyInit, Effect: corev1.TaintEffectNoSchedule}
+	taintDecommissioning = corev1.Taint{Key: TaintKeyNodeDecommissioning, Effect: corev1.TaintEffectNoSchedule}
+	taintEvacuate        = corev1.Taint{Key: TaintKeyNodeEvacuate, Effect: corev1.TaintEffectNoExecute}
+	taintScalingDown     = corev1.Taint{Key: TaintKeyNodeScalingDown, Effect: corev1.TaintEffectNoSchedule}
+
+	// lifecycleMarkersByState lists the labels (set to "true") and taints a node carries in each state
+	lifecycleMarkersByState = map[LifecycleState]lifecycleMarkers{
+		LifecycleStateInitializing: {
+			taints: []corev1.Taint{taintInit},
+		},
+		LifecycleStateActive: {},
+		LifecycleStateDecommissioning: {
+			labels: []string{LabelKeyDecommissioning},
+			taints: []corev1.Taint{taintDecommissioning},
+		},
+		LifecycleStateEvacuating: {
+			labels: []string{LabelKeyDecommissioning},
+			taints: []corev1.Taint{taintDecommissioning, taintEvacuate},
+		},
+		LifecycleStateScalingDown: {
+			labels: []string{LabelKeyRemovable},
+			taints: []corev1.Taint{taintScalingDown},
+		},
+		LifecycleStateTerminating: {
+			labels: []string{LabelKeyTerminating},
+			taints: []corev1.Taint{taintEvacuate},
+		},
+	}
+
+	// lifecycleTransitions lists the legal transitions out of each state
+	lifecycleTransitions = map[LifecycleState][]LifecycleState{
+		LifecycleStateInitializing:    {LifecycleStateActive, LifecycleStateTerminating},
+		LifecycleStateActive:          {LifecycleStateDecommissioning, LifecycleStateScalingDown, LifecycleStateTerminating},
+		LifecycleStateDecommissioning: {LifecycleStateActive, LifecycleStateEvacuating, LifecycleStateTerminating},
+		LifecycleStateEvacuating:      {LifecycleStateDecommissioning, LifecycleStateActive, LifecycleStateTerminating},
+		LifecycleStateScalingDown:     {LifecycleStateActive, LifecycleStateTerminating},
+		LifecycleStateTerminating:     {},
+	}
+)
+
+// GetLifecycleState derives the lifecycle state of a node from its labels and taints. Half-transitioned nodes
+// are assigned the most advanced state any of their markers indicates; use CheckLifecycle to find those.
+func GetLifecycleState(node *corev1.Node) LifecycleState {
+	switch {
+	case hasLifecycleLabel(node, LabelKeyTerminating):
+		return LifecycleStateTerminating
+	case hasLifecycleLabel(node, LabelKeyRemovable) || hasTaintKey(node, TaintKeyNodeScalingDown):
+		return LifecycleStateScalingDown
+	case hasTaintKey(node, TaintKeyNodeEvacuate):
+		return LifecycleStateEvacuating
+	case hasLifecycleLabel(node, LabelKeyDecommissioning) || hasTaintKey(node, TaintKeyNodeDecommissioning):
+		return LifecycleStateDecommissioning
+	case hasTaintKey(node, TaintKeyInit):
+		return LifecycleStateInitializing
+	default:
+		return LifecycleStateActive
+	}
+}
+
+// CanTransition returns true if a node may move from one lifecycle state to another.
+// Staying in the same state is always allowed.
+func CanTransition(from, to LifecycleState) bool {
+	if from == to {
+		return true
+	}
+	for _, state := range lifecycleTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionLifecycle moves a node to the given lifecycle state, replacing all its lifecycle labels and taints
+// with the ones of the target state. Transitioning to the current state repairs half-transitioned nodes.
+// The node is left untouched if the transition is not legal, or if a node labeled as unremovable would be
+// scaled down or terminated.
+func TransitionLifecycle(node *corev1.Node, to LifecycleState) error {
+	markers, ok := lifecycleMarkersByState[to]
+	if !ok {
+		return fmt.Errorf("unknown lifecycle state %s", to)
+	}
+
+	from := GetLifecycleState(node)
+	if !CanTransition(from, to) {
+		return fmt.Errorf("node %s cannot transition from %s to %s", node.Name, from, to)
+	}
+	if (to == LifecycleStateScalingDown || to == LifecycleStateTerminating) && hasLifecycleLabel(node, LabelKeyUnremovable) {
+		return fmt.Errorf("node %s is labeled with %s and cannot transition to %s", node.Name, LabelKeyUnremovable, to)
+	}
+
+	labels := map[string]string{}
+	for key, val := range node.Labels {
+		if !containsString(lifecycleLabelKeys, key) {
+			labels[key] = val
+		}
+	}
+	for _, key := range markers.labels {
+		labels[key] = "true"
+	}
+
+	var taints []corev1.Taint
+	for _, taint := range node.Spec.Taints {
+		if !containsString(lifecycleTaintKeys, taint.Key) || containsTaint(markers.taints, taint) {
+			taints = append(taints, taint)
+		}
+	}
+	for _, taint := range markers.taints {
+		if !containsTaint(taints, taint) {
+			newTaint := taint
+			if newTaint.Effect == corev1.TaintEffectNoExecute {
+				now := metav1.Now()
+				newTaint.TimeAdded = &now
+			}
+			taints = append(taints, newTaint)
+		}
+	}
+
+	node.Labels = labels
+	node.Spec.Taints = taints
+
+	return nil
+}
+
+// LifecycleReport describes how the lifecycle markers of a node deviate from the ones expected in its state
+type LifecycleReport struct {
+	State LifecycleState
+	// MissingLabels and MissingTaints are markers of the node's state that the node doesn't carry
+	MissingLabels []string
+	MissingTaints []corev1.Taint
+	// UnexpectedLabels and UnexpectedTaints are lifecycle markers the node carries that don't belong to its state
+	UnexpectedLabels []string
+	UnexpectedTaints []corev1.Taint
+}
+
+// Consistent returns true if the node carries exactly the lifecycle markers of its state
+func (r LifecycleReport) Consistent() bool {
+	return len(r.MissingLabels) == 0 && len(r.MissingTaints) == 0 && len(r.UnexpectedLabels) == 0 && len(r.UnexpectedTaints) == 0
+}
+
+// CheckLifecycle reports the lifecycle markers a node is missing or carries in excess for its state,
+// such as a decommissioning label without the matching taint
+func CheckLifecycle(node *corev1.Node) LifecycleReport {
+	state := GetLifecycleState(node)
+	markers := lifecycleMarkersByState[state]
+	report := LifecycleReport{State: state}
+
+	for _, key := range markers.labels {
+		if !hasLifecycleLabel(node, key) {
+			report.MissingLabels = append(report.MissingLabels, key)
+		}
+	}
+	for _, key := range lifecycleLabelKeys {
+		if hasLifecycleLabel(node, key) && !containsString(markers.labels, key) {
+			report.UnexpectedLabels = append(report.UnexpectedLabels, key)
+		}
+	}
+
+	for _, taint := range markers.taints {
+		if !containsTaint(node.Spec.Taints, taint) {
+			report.MissingTaints = append(report.MissingTaints, taint)
+		}
+	}
+	for _, taint := range node.Spec.Taints {
+		if containsString(lifecycleTaintKeys, taint.Key) && !containsTaint(markers.taints, taint) {
+			report.UnexpectedTaints = append(report.UnexpectedTaints, taint)
+		}
+	}
+
+	return report
+}
+
+func hasLifecycleLabel(node *corev1.Node, key string) bool {
+	val, ok := node.Labels[key]
+	if !ok {
+		return false
+	}
+	set, err := strconv.ParseBool(val)
+	return err == nil && set
+}
+
+func hasTaintKey(node *corev1.Node, key string) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// containsTaint returns true if a taint with the same key and effect is in the list
+func containsTaint(taints []corev1.Taint, taint corev1.Taint) bool {
+	for i := range taints {
+		if taints[i].Key == taint.Key && taints[i].Effect == taint.Effect {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package node
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetLifecycleState(t *testing.T) {
+	tests := []struct {
+		desc   string
+		labels map[string]string
+		taints []corev1.Taint
+		want   LifecycleState
+	}{
+		{
+			desc: "no markers",
+			want: LifecycleStateActive,
+		},
+		{
+			desc:   "uninitialized",
+			taints: []corev1.Taint{taintInit},
+			want:   LifecycleStateInitializing,
+		},
+		{
+			desc:   "decommissioning label only",
+			labels: map[string]string{LabelKeyDecommissioning: "true"},
+			want:   LifecycleStateDecommissioning,
+		},
+		{
+			desc:   "decommissioning label set to false",
+			labels: map[string]string{LabelKeyDecommissioning: "false"},
+			want:   LifecycleStateActive,
+		},
+		{
+			desc:   "evacuating",
+			labels: map[string]string{LabelKeyDecommissioning: "true"},
+			taints: []corev1.Taint{taintDecommissioning, taintEvacuate},
+			want:   LifecycleStateEvacuating,
+		},
+		{
+			desc:   "scaling down",
+			taints: []corev1.Taint{taintScalingDown},
+			want:   LifecycleStateScalingDown,
+		},
+		{
+			desc:   "terminating wins over everything else",
+			labels: map[string]string{LabelKeyTerminating: "true", LabelKeyDecommissioning: "true"},
+			taints: []corev1.Taint{taintInit, taintScalingDown},
+			want:   LifecycleStateTerminating,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			node := buildNode(nil, tt.labels)
+			node.Spec.Taints = tt.taints
+			assert.Equal(t, GetLifecycleState(node), tt.want)
+		})
+	}
+}
+
+func TestTransitionLifecycle(t *testing.T) {
+	otherTaint := corev1.Taint{Key: TaintKeyTier, Value: "flex", Effect: corev1.TaintEffectNoSchedule}
+	node := buildNode(nil, map[string]string{LabelKeyResourcePool: "elastic"})
+	node.Spec.Taints = []corev1.Taint{taintInit, otherTaint}
+
+	assert.NilError(t, TransitionLifecycle(node, LifecycleStateActive))
+	assert.Equal(t, GetLifecycleState(node), LifecycleStateActive)
+	assert.DeepEqual(t, node.Spec.Taints, []corev1.Taint{otherTaint})
+
+	assert.NilError(t, TransitionLifecycle(node, LifecycleStateDecommissioning))
+	assert.NilError(t, TransitionLifecycle(node, LifecycleStateEvacuating))
+	assert.Equal(t, GetLifecycleState(node), LifecycleStateEvacuating)
+	assert.Equal(t, node.Labels[LabelKeyDecommissioning], "true")
+	assert.Equal(t, node.Labels[LabelKeyResourcePool], "elastic")
+	assert.Equal(t, len(node.Spec.Taints), 3)
+	assert.Assert(t, CheckLifecycle(node).Consistent())
+
+	assert.NilError(t, TransitionLifecycle(node, LifecycleStateTerminating))
+	assert.Equal(t, GetLifecycleState(node), LifecycleStateTerminating)
+	_, ok := node.Labels[LabelKeyDecommissioning]
+	assert.Assert(t, !ok)
+	assert.Assert(t, CheckLifecycle(node).Consistent())
+
+	err := TransitionLifecycle(node, LifecycleStateActive)
+	assert.ErrorContains(t, err, "node i-0123456789abcdef0 cannot transition from Terminating to Active")
+}
+
+func TestTransitionLifecycleUnremovable(t *testing.T) {
+	node := buildNode(nil, map[string]string{LabelKeyUnremovable: "true"})
+
+	err := TransitionLifecycle(node, LifecycleStateScalingDown)
+	assert.ErrorContains(t, err, "is labeled with node.titus.netflix.com/unremovable and cannot transition to ScalingDown")
+	assert.Equal(t, GetLifecycleState(node), LifecycleStateActive)
+	assert.Equal(t, len(node.Spec.Taints), 0)
+
+	assert.NilError(t, TransitionLifecycle(node, LifecycleStateDecommissioning))
+}
+
+func TestTransitionLifecycleIllegal(t *testing.T) {
+	node := buildNode(nil, nil)
+	node.Spec.Taints = []corev1.Taint{taintInit}
+
+	err := TransitionLifecycle(node, LifecycleStateEvacuating)
+	assert.ErrorContains(t, err, "cannot transition from Initializing to Evacuating")
+	assert.DeepEqual(t, node.Spec.Taints, []corev1.Taint{taintInit})
+
+	err = TransitionLifecycle(node, LifecycleState("Unknown"))
+	assert.ErrorContains(t, err, "unknown lifecycle state Unknown")
+}
+
+func TestCheckLifecycle(t *testing.T) {
+	// Labeled as decommissioning, but the taint was never added, and the scaler left an init taint behind
+	node := buildNode(nil, map[string]string{LabelKeyDecommissioning: "true"})
+	node.Spec.Taints = []corev1.Taint{taintInit}
+
+	report := CheckLifecycle(node)
+	assert.Assert(t, !report.Consistent())
+	assert.DeepEqual(t, report, LifecycleReport{
+		State:            LifecycleStateDecommissioning,
+		MissingTaints:    []corev1.Taint{taintDecommissioning},
+		UnexpectedTaints: []corev1.Taint{taintInit},
+	})
+
+	// Transitioning to the current state repairs the node
+	assert.NilError(t, TransitionLifecycle(node, report.State))
+	assert.Assert(t, CheckLifecycle(node).Consistent())
+}
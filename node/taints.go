@@ -5,6 +5,7 @@ const (
 	TaintKeyFarzone             = "node.titus.netflix.com/farzone"
 	TaintKeyGPUNode             = "node.titus.netflix.com/gpu"
 	TaintKeyInit                = "node.titus.netflix.com/uninitialized"
+	TaintKeyKvm                 = "node.titus.netflix.com/kvm"
 	TaintKeyNodeEvacuate        = "taint.titus.netflix.com/evacuate"
 	TaintKeyNodeProblem         = "taint.titus.netflix.com/nodeProblem"
 	TaintKeyScheduler           = "node.titus.netflix.com/scheduler"
@@ -0,0 +1,127 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Netflix/titus-kube-common/internal/termination"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrAlreadyTerminating is returned by Terminate when the node is already being deleted, or another caller
+// already recorded a termination reason on it
+var ErrAlreadyTerminating = termination.ErrAlreadyTerminating
+
+// nodeClient abstracts the few operations termination needs on a single node, so that it can run on top of
+// both the controller-runtime and the client-go clients
+type nodeClient interface {
+	termination.Client
+	delete(ctx context.Context, uid types.UID) error
+}
+
+// Terminate records the termination reason and caller on the node (see AnnotationKeyNodeTerminationReason),
+// then deletes it. If the deletion fails, the annotations are removed again so that Terminate can be retried.
+// If the node was modified concurrently, the latest version is checked, and Terminate stops with
+// ErrAlreadyTerminating if the node is already being deleted or has a termination reason.
+func Terminate(ctx context.Context, c client.Client, node *corev1.Node, reason, caller string) error {
+	return terminate(ctx, &controllerRuntimeNodeClient{client: c, name: node.Name}, node, reason, caller)
+}
+
+// TerminateWithClientset is the same as Terminate, using a client-go clientset
+func TerminateWithClientset(ctx context.Context, clientset kubernetes.Interface, node *corev1.Node, reason, caller string) error {
+	return terminate(ctx, &clientsetNodeClient{nodes: clientset.CoreV1().Nodes(), name: node.Name}, node, reason, caller)
+}
+
+func terminate(ctx context.Context, nodes nodeClient, node *corev1.Node, reason, caller string) error {
+	if reason == "" {
+		return errors.New("node termination reason must not be empty")
+	}
+	if caller == "" {
+		return errors.New("node termination caller must not be empty")
+	}
+
+	annotations := map[string]string{
+		AnnotationKeyNodeTerminationReason:   reason,
+		AnnotationKeyNodeTerminationByCaller: caller,
+	}
+	recorded, err := termination.Record(ctx, nodes, node, AnnotationKeyNodeTerminationReason, annotations)
+	if err != nil {
+		return fmt.Errorf("could not record termination reason on node %s: %w", node.Name, err)
+	}
+
+	if err := nodes.delete(ctx, node.UID); err != nil {
+		err = fmt.Errorf("could not delete node %s: %w", node.Name, err)
+		// The node stays, so it must not look terminated, or a retry would fail with ErrAlreadyTerminating
+		if revertErr := termination.Revert(ctx, nodes, recorded, annotations); revertErr != nil {
+			return fmt.Errorf("%w (and could not remove the termination reason: %s)", err, revertErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+type controllerRuntimeNodeClient struct {
+	client client.Client
+	name   string
+}
+
+func (c *controllerRuntimeNodeClient) Get(ctx context.Context) (metav1.Object, error) {
+	node := &corev1.Node{}
+	if err := c.client.Get(ctx, types.NamespacedName{Name: c.name}, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (c *controllerRuntimeNodeClient) Patch(ctx context.Context, data []byte) (metav1.Object, error) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: c.name}}
+	if err := c.client.Patch(ctx, node, client.RawPatch(types.MergePatchType, data)); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (c *controllerRuntimeNodeClient) delete(ctx context.Context, uid types.UID) error {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: c.name}}
+	var opts []client.DeleteOption
+	if uid != "" {
+		opts = append(opts, client.Preconditions{UID: &uid})
+	}
+	return c.client.Delete(ctx, node, opts...)
+}
+
+type clientsetNodeClient struct {
+	nodes typedcorev1.NodeInterface
+	name  string
+}
+
+func (c *clientsetNodeClient) Get(ctx context.Context) (metav1.Object, error) {
+	node, err := c.nodes.Get(ctx, c.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (c *clientsetNodeClient) Patch(ctx context.Context, data []byte) (metav1.Object, error) {
+	node, err := c.nodes.Patch(ctx, c.name, types.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (c *clientsetNodeClient) delete(ctx context.Context, uid types.UID) error {
+	opts := metav1.DeleteOptions{}
+	if uid != "" {
+		opts.Preconditions = &metav1.Preconditions{UID: &uid}
+	}
+	return c.nodes.Delete(ctx, c.name, opts)
+}
@@ -0,0 +1,143 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTerminate(t *testing.T) {
+	ctx := context.Background()
+	stored := buildNode(map[string]string{AnnotationKeyASG: "titusagent-v001"}, nil)
+	// Keep the node around after deletion, so the annotations can be checked
+	stored.Finalizers = []string{"test.netflix.com/finalizer"}
+	c := fake.NewClientBuilder().WithObjects(stored).Build()
+
+	// A stale copy of the node, which forces a conflict on the first attempt
+	stale := stored.DeepCopy()
+	stale.ResourceVersion = "1"
+
+	err := Terminate(ctx, c, stale, "node is unhealthy", "node-problem-controller")
+	assert.NilError(t, err)
+
+	node := &corev1.Node{}
+	assert.NilError(t, c.Get(ctx, types.NamespacedName{Name: stored.Name}, node))
+	assert.Equal(t, node.Annotations[AnnotationKeyNodeTerminationReason], "node is unhealthy")
+	assert.Equal(t, node.Annotations[AnnotationKeyNodeTerminationByCaller], "node-problem-controller")
+	assert.Equal(t, node.Annotations[AnnotationKeyASG], "titusagent-v001")
+	assert.Assert(t, node.DeletionTimestamp != nil)
+}
+
+func TestTerminateRequiresReason(t *testing.T) {
+	ctx := context.Background()
+	stored := buildNode(nil, nil)
+	c := fake.NewClientBuilder().WithObjects(stored).Build()
+
+	err := Terminate(ctx, c, stored, "", "node-problem-controller")
+	assert.ErrorContains(t, err, "node termination reason must not be empty")
+	err = Terminate(ctx, c, stored, "node is unhealthy", "")
+	assert.ErrorContains(t, err, "node termination caller must not be empty")
+
+	// The node was not deleted
+	assert.NilError(t, c.Get(ctx, types.NamespacedName{Name: stored.Name}, &corev1.Node{}))
+}
+
+func TestTerminateNotFound(t *testing.T) {
+	c := fake.NewClientBuilder().Build()
+
+	err := Terminate(context.Background(), c, buildNode(nil, nil), "node is unhealthy", "node-problem-controller")
+	assert.ErrorContains(t, err, "could not record termination reason on node i-0123456789abcdef0")
+	assert.Assert(t, apierrors.IsNotFound(err))
+}
+
+func TestTerminateWithClientset(t *testing.T) {
+	ctx := context.Background()
+	stored := buildNode(nil, nil)
+	stored.ResourceVersion = "5"
+	clientset := k8sfake.NewSimpleClientset(stored)
+
+	conflicts := 0
+	clientset.PrependReactor("patch", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "nodes"}, stored.Name, nil)
+	})
+
+	err := TerminateWithClientset(ctx, clientset, stored, "node is unhealthy", "node-problem-controller")
+	assert.NilError(t, err)
+	assert.Equal(t, conflicts, 1)
+
+	var verbs []string
+	for _, action := range clientset.Actions() {
+		verbs = append(verbs, action.GetVerb())
+	}
+	assert.DeepEqual(t, verbs, []string{"patch", "get", "patch", "delete"})
+
+	_, err = clientset.CoreV1().Nodes().Get(ctx, stored.Name, metav1.GetOptions{})
+	assert.Assert(t, apierrors.IsNotFound(err))
+}
+
+func TestTerminateConcurrentTermination(t *testing.T) {
+	ctx := context.Background()
+	stored := buildNode(map[string]string{
+		AnnotationKeyNodeTerminationReason:   "node is being decommissioned",
+		AnnotationKeyNodeTerminationByCaller: "node-decommissioner",
+	}, nil)
+	c := fake.NewClientBuilder().WithObjects(stored).Build()
+
+	// The stale copy doesn't have the annotations of the concurrent caller
+	stale := buildNode(nil, nil)
+	stale.ResourceVersion = "1"
+
+	err := Terminate(ctx, c, stale, "node is unhealthy", "node-problem-controller")
+	assert.Assert(t, errors.Is(err, ErrAlreadyTerminating))
+	assert.ErrorContains(t, err, `termination reason "node is being decommissioned" is already recorded`)
+
+	node := &corev1.Node{}
+	assert.NilError(t, c.Get(ctx, types.NamespacedName{Name: stored.Name}, node))
+	assert.Equal(t, node.Annotations[AnnotationKeyNodeTerminationReason], "node is being decommissioned")
+	assert.Equal(t, node.Annotations[AnnotationKeyNodeTerminationByCaller], "node-decommissioner")
+	assert.Assert(t, node.DeletionTimestamp == nil)
+}
+
+func TestTerminateDeleteFails(t *testing.T) {
+	ctx := context.Background()
+	stored := buildNode(map[string]string{AnnotationKeyASG: "titusagent-v001"}, nil)
+	clientset := k8sfake.NewSimpleClientset(stored)
+
+	failures := 0
+	clientset.PrependReactor("delete", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if failures > 0 {
+			return false, nil, nil
+		}
+		failures++
+		return true, nil, apierrors.NewServiceUnavailable("try again later")
+	})
+
+	err := TerminateWithClientset(ctx, clientset, stored, "node is unhealthy", "node-problem-controller")
+	assert.ErrorContains(t, err, "could not delete node i-0123456789abcdef0: try again later")
+	assert.Assert(t, !errors.Is(err, ErrAlreadyTerminating))
+
+	// The termination reason was removed, so the node doesn't look terminated
+	node, err := clientset.CoreV1().Nodes().Get(ctx, stored.Name, metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, node.Annotations, map[string]string{AnnotationKeyASG: "titusagent-v001"})
+
+	// And the same caller can retry
+	assert.NilError(t, TerminateWithClientset(ctx, clientset, node, "node is unhealthy", "node-problem-controller"))
+	_, err = clientset.CoreV1().Nodes().Get(ctx, stored.Name, metav1.GetOptions{})
+	assert.Assert(t, apierrors.IsNotFound(err))
+}
@@ -61,6 +61,9 @@ const (
 
 	// AnnotationKeyPodSchemaVersion is an integer specifying what schema version a pod was created with
 	AnnotationKeyPodSchemaVersion = "pod.netflix.com/pod-schema-version"
+	// AnnotationKeyPodMainContainerName names the main container of the pod, for pods where it can't be
+	// guessed from the container names
+	AnnotationKeyPodMainContainerName = "pod.netflix.com/main-container-name"
 
 	// Workload-specific fields
 
@@ -78,6 +81,10 @@ const (
 	AnnotationKeyJobApplicationName        = "v3.job.titus.netflix.com/application"
 	AnnotationKeyJobDisruptionBudgetPolicy = "v3.job.titus.netflix.com/disruption-budget-policy"
 
+	// Values of AnnotationKeyJobType
+	JobTypeBatch   = "BATCH"
+	JobTypeService = "SERVICE"
+
 	// AnnotationKeyPodTitusContainerInfo - to be removed once VK supports the full pod spec
 	AnnotationKeyPodTitusContainerInfo = "pod.titus.netflix.com/container-info"
 	// AnnotationKeyPodTitusEntrypointShellSplitting tells the executor to preserve the legacy shell splitting behaviour
@@ -220,13 +227,16 @@ const (
 
 	AnnotationKeyLogKeepLocalFile       = "log.netflix.com/keep-local-file-after-upload"
 	AnnotationKeyLogS3BucketName        = "log.netflix.com/s3-bucket-name"
-	AnnotationKeyLogS3PathPrefix        = "log.netflix.com/s3-path-prefix"
 	AnnotationKeyLogS3WriterIAMRole     = "log.netflix.com/s3-writer-iam-role"
 	AnnotationKeyLogStdioCheckInterval  = "log.netflix.com/stdio-check-interval"
 	AnnotationKeyLogUploadThresholdTime = "log.netflix.com/upload-threshold-time"
 	AnnotationKeyLogUploadCheckInterval = "log.netflix.com/upload-check-interval"
 	AnnotationKeyLogUploadRegexp        = "log.netflix.com/upload-regexp"
 
+	// AnnotationKeyLogS3PathPrefix is the prefix of the S3 keys of the logs, used as is. The job ID and the
+	// task ID are appended to it, see LogConfig.S3KeyPrefix.
+	AnnotationKeyLogS3PathPrefix = "log.netflix.com/s3-path-prefix"
+
 	// sidecar configuration
 
 	AnnotationKeySuffixSidecars                      = "platform-sidecars.netflix.com"
@@ -361,6 +371,10 @@ func parseAnnotations(pod *corev1.Pod, pConf *Config) error {
 			key:   AnnotationKeyPrefixAppArmor + "/" + userCtr.Name,
 			field: &pConf.AppArmorProfile,
 		},
+		{
+			key:   AnnotationKeyAZ,
+			field: &pConf.AvailabilityZone,
+		},
 		{
 			key:   AnnotationKeyWorkloadDetail,
 			field: &pConf.WorkloadDetail,
@@ -437,10 +451,26 @@ func parseAnnotations(pod *corev1.Pod, pConf *Config) error {
 			key:   AnnotationKeyPodHostnameStyle,
 			field: &pConf.HostnameStyle,
 		},
+		{
+			key:   AnnotationKeyPodPriorityClassIntent,
+			field: &pConf.PriorityClassIntent,
+		},
+		{
+			key:   AnnotationKeyPodMainContainerName,
+			field: &pConf.MainContainerName,
+		},
+		{
+			key:   AnnotationKeyRequestedTroughName,
+			field: &pConf.RequestedTroughName,
+		},
 		{
 			key:   AnnotationKeyPodSchedPolicy,
 			field: &pConf.SchedPolicy,
 		},
+		{
+			key:   AnnotationKeyPodScheduledTroughName,
+			field: &pConf.ScheduledTroughName,
+		},
 		{
 			key:   AnnotationKeySecurityWorkloadMetadata,
 			field: &pConf.WorkloadMetadata,
@@ -475,11 +505,24 @@ func parseAnnotations(pod *corev1.Pod, pConf *Config) error {
 	}
 
 	if hostnameStyle, ok := annotations[AnnotationKeyPodHostnameStyle]; ok {
-		if hostnameStyle != "ec2" && hostnameStyle != "" {
+		if !IsValidHostnameStyle(hostnameStyle) {
 			err = multierror.Append(err, fmt.Errorf("%s annotation is not a valid hostname style: %s", AnnotationKeyPodHostnameStyle, hostnameStyle))
 		}
 	}
 
+	if IsScheduledInTrough(pod) {
+		scheduledInTrough := true
+		pConf.ScheduledInTrough = &scheduledInTrough
+	}
+
+	if mainContainerName, ok := annotations[AnnotationKeyPodMainContainerName]; ok {
+		if GetContainerByName(pod, mainContainerName) == nil {
+			err = multierror.Append(err, fmt.Errorf("%s annotation is not the name of a container: %s", AnnotationKeyPodMainContainerName, mainContainerName))
+		} else if IsPlatformSidecarContainer(mainContainerName, pod) {
+			err = multierror.Append(err, fmt.Errorf("%s annotation is the name of a platform sidecar: %s", AnnotationKeyPodMainContainerName, mainContainerName))
+		}
+	}
+
 	for _, an := range boolAnnotations {
 		val, ok := annotations[an.key]
 		if ok {
@@ -578,22 +621,29 @@ func parseAnnotations(pod *corev1.Pod, pConf *Config) error {
 		pConf.SubnetIDs = &subIDs
 	}
 
-	if envVal, ok := annotations[AnnotationKeyPodTitusSystemEnvVarNames]; ok {
-		envsSplit := strings.Split(strings.TrimSpace(envVal), ",")
-		for _, env := range envsSplit {
-			pConf.SystemEnvVarNames = append(pConf.SystemEnvVarNames, strings.TrimSpace(env))
-		}
+	pConf.SystemEnvVarNames = envVarNames(annotations, AnnotationKeyPodTitusSystemEnvVarNames)
+	pConf.InjectedEnvVarNames = envVarNames(annotations, AnnotationKeyPodInjectedEnvVarNames)
+
+	if pConf.SchedPolicy != nil && *pConf.SchedPolicy != "batch" && *pConf.SchedPolicy != "idle" {
+		err = multierror.Append(err, fmt.Errorf("%s annotation is not a valid scheduler policy: %s", AnnotationKeyPodSchedPolicy, *pConf.SchedPolicy))
 	}
 
-	if envVal, ok := annotations[AnnotationKeyPodInjectedEnvVarNames]; ok {
-		envsSplit := strings.Split(strings.TrimSpace(envVal), ",")
-		for _, env := range envsSplit {
-			pConf.InjectedEnvVarNames = append(pConf.InjectedEnvVarNames, strings.TrimSpace(env))
+	if latencyVal, ok := annotations[AnnotationKeySchedLatencyReq]; ok {
+		latency := SchedLatency(latencyVal)
+		if latency.IsValid() {
+			pConf.SchedLatencyReq = &latency
+		} else {
+			err = multierror.Append(err, fmt.Errorf("%s annotation is not a valid scheduling latency: %s", AnnotationKeySchedLatencyReq, latencyVal))
 		}
 	}
 
-	if pConf.SchedPolicy != nil && *pConf.SchedPolicy != "batch" && *pConf.SchedPolicy != "idle" {
-		err = multierror.Append(err, fmt.Errorf("%s annotation is not a valid scheduler policy: %s", AnnotationKeyPodSchedPolicy, *pConf.SchedPolicy))
+	if spreadingVal, ok := annotations[AnnotationKeySchedSpreadingReq]; ok {
+		spreading := SchedSpreading(spreadingVal)
+		if spreading.IsValid() {
+			pConf.SchedSpreadingReq = &spreading
+		} else {
+			err = multierror.Append(err, fmt.Errorf("%s annotation is not a valid scheduling spreading: %s", AnnotationKeySchedSpreadingReq, spreadingVal))
+		}
 	}
 
 	if err == nil {
@@ -684,6 +734,8 @@ func IsMockPod(pod *corev1.Pod) bool {
 	return ok
 }
 
+// IsScheduledInTrough returns true if the scheduler recorded that the pod was scheduled in a trough.
+// Only the presence of the annotation matters, not its value.
 func IsScheduledInTrough(pod *corev1.Pod) bool {
 	_, ok := pod.Annotations[AnnotationKeyPodScheduledInTrough]
 	return ok
@@ -0,0 +1,108 @@
+package pod
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// EffectiveCommand returns the argv the main container is started with, from its Command and Args.
+// With the legacy shell splitting behaviour (Config.EntrypointShellSplitting), every element of Command and
+// Args is split into words with ShellSplit, since legacy jobs passed the whole entrypoint as a single string.
+// Without it, Command and Args are used as is. The image ENTRYPOINT and CMD aren't known here, so a container
+// without Command only gets its Args.
+func EffectiveCommand(pod *corev1.Pod, cfg *Config) ([]string, error) {
+	mainContainer := GetMainUserContainer(pod)
+	if mainContainer == nil {
+		return nil, errors.New("could not find main container in pod")
+	}
+
+	argv := make([]string, 0, len(mainContainer.Command)+len(mainContainer.Args))
+	argv = append(argv, mainContainer.Command...)
+	argv = append(argv, mainContainer.Args...)
+
+	if cfg == nil || cfg.EntrypointShellSplitting == nil || !*cfg.EntrypointShellSplitting {
+		return argv, nil
+	}
+
+	split := make([]string, 0, len(argv))
+	for _, arg := range argv {
+		words, err := ShellSplit(arg)
+		if err != nil {
+			return nil, fmt.Errorf("could not split the command of container %s: %w", mainContainer.Name, err)
+		}
+		split = append(split, words...)
+	}
+	return split, nil
+}
+
+// ShellSplit splits a string into words following the POSIX shell quoting rules: words are separated by
+// unquoted blanks, single quotes preserve everything up to the next single quote, double quotes preserve
+// everything but backslash escapes of $, `, ", \ and newline, and a backslash outside of quotes escapes the
+// next character. Variables, globs and other expansions are not performed.
+func ShellSplit(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	// inWord is true once a word has started, so that quoted empty strings produce a word
+	inWord := false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case ' ', '\t', '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case '\\':
+			if i+1 >= len(s) {
+				return nil, fmt.Errorf("%q ends with an unescaped backslash", s)
+			}
+			i++
+			// A backslash followed by a newline is a line continuation
+			if s[i] != '\n' {
+				word.WriteByte(s[i])
+				inWord = true
+			}
+		case '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("%q has an unbalanced single quote at position %d", s, i)
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+		case '"':
+			start := i
+			closed := false
+			for i++; i < len(s); i++ {
+				if s[i] == '"' {
+					closed = true
+					break
+				}
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("$`\"\\\n", s[i+1]) >= 0 {
+					i++
+					if s[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(s[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("%q has an unbalanced double quote at position %d", s, start)
+			}
+			inWord = true
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
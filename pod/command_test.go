@@ -0,0 +1,67 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestShellSplit(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "", expected: nil},
+		{input: "  \t ", expected: nil},
+		{input: "/bin/sh -c  'echo hello'", expected: []string{"/bin/sh", "-c", "echo hello"}},
+		{input: `echo "it's \"quoted\" \$HOME \n"`, expected: []string{"echo", `it's "quoted" $HOME \n`}},
+		{input: `a\ b c\\d`, expected: []string{"a b", `c\d`}},
+		{input: `'' ""`, expected: []string{"", ""}},
+		{input: `pre'fix'"suffix"`, expected: []string{"prefixsuffix"}},
+		{input: "first \\\nsecond", expected: []string{"first", "second"}},
+		{input: `'single \ keeps backslashes'`, expected: []string{`single \ keeps backslashes`}},
+	}
+
+	for _, tt := range tests {
+		words, err := ShellSplit(tt.input)
+		assert.NilError(t, err, tt.input)
+		assert.DeepEqual(t, words, tt.expected)
+	}
+}
+
+func TestShellSplitInvalid(t *testing.T) {
+	tests := []struct {
+		input    string
+		errMatch string
+	}{
+		{input: `echo 'hello`, errMatch: `"echo 'hello" has an unbalanced single quote at position 5`},
+		{input: `echo "hello`, errMatch: `"echo \"hello" has an unbalanced double quote at position 5`},
+		{input: `echo "hello\"`, errMatch: "has an unbalanced double quote at position 5"},
+		{input: `echo \`, errMatch: `"echo \\" ends with an unescaped backslash`},
+	}
+
+	for _, tt := range tests {
+		_, err := ShellSplit(tt.input)
+		assert.ErrorContains(t, err, tt.errMatch)
+	}
+}
+
+func TestEffectiveCommand(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{})
+	pod.Spec.Containers[0].Command = []string{"/bin/sh -c"}
+	pod.Spec.Containers[0].Args = []string{"'echo hello' world"}
+
+	argv, err := EffectiveCommand(pod, &Config{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, argv, []string{"/bin/sh -c", "'echo hello' world"})
+
+	cfg := &Config{EntrypointShellSplitting: ptr.BoolPtr(true)}
+	argv, err = EffectiveCommand(pod, cfg)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, argv, []string{"/bin/sh", "-c", "echo hello", "world"})
+
+	pod.Spec.Containers[0].Args = []string{"'echo hello"}
+	_, err = EffectiveCommand(pod, cfg)
+	assert.ErrorContains(t, err, "could not split the command of container task-id-in-container: \"'echo hello\" has an unbalanced single quote at position 0")
+}
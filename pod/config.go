@@ -2,6 +2,7 @@ package pod
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"time"
 
@@ -17,6 +18,7 @@ type Config struct {
 	AssignIPv6Address        *bool
 	AccountID                *string
 	AppArmorProfile          *string
+	AvailabilityZone         *string
 	CapacityGroup            *string
 	CPUBurstingEnabled       *bool
 	ContainerInfo            *string
@@ -44,17 +46,25 @@ type Config struct {
 	LogS3WriterIAMRole       *string
 	LogS3BucketName          *string
 	LogS3PathPrefix          *string
+	MainContainerName        *string
 	NetworkMode              *string
 	NetworkBurstingEnabled   *bool
 	NflxIMDSEnabled          *bool
 	OomScoreAdj              *int32
 	PodSchemaVersion         *uint32
+	PriorityClassIntent      *string
+	RequestedTroughName      *string
 	ResourceCPU              *resource.Quantity
 	ResourceDisk             *resource.Quantity
 	ResourceGPU              *resource.Quantity
 	ResourceMemory           *resource.Quantity
 	ResourceNetwork          *resource.Quantity
+	ResourcePool             *string
+	SchedLatencyReq          *SchedLatency
 	SchedPolicy              *string
+	SchedSpreadingReq        *SchedSpreading
+	ScheduledInTrough        *bool
+	ScheduledTroughName      *string
 	SeccompAgentNetEnabled   *bool
 	SeccompAgentPerfEnabled  *bool
 	TrafficSteeringEnabled   *bool
@@ -109,13 +119,27 @@ func parsePodFields(pod *corev1.Pod, pConf *Config) error {
 		return errors.New("could not find main container in pod")
 	}
 
-	resources := mainContainer.Resources.Limits
+	resources, err := resourceCommon.Normalize(mainContainer.Resources.Limits)
+	if err != nil {
+		return fmt.Errorf("container %s has invalid resource limits: %w", mainContainer.Name, err)
+	}
 	pConf.ResourceCPU = resourcePtr(resources, corev1.ResourceCPU)
 	pConf.ResourceDisk = resourcePtr(resources, corev1.ResourceEphemeralStorage)
 	pConf.ResourceGPU = resourcePtr(resources, resourceCommon.ResourceNameGpu)
 	pConf.ResourceMemory = resourcePtr(resources, corev1.ResourceMemory)
 	pConf.ResourceNetwork = resourcePtr(resources, resourceCommon.ResourceNameNetwork)
-	// XXX: do we need the legacy gpu and network resource names, too?
+
+	byteUnits, err := ByteUnitsEnabled(pod)
+	if err != nil {
+		return err
+	}
+	if !byteUnits {
+		pConf.ResourceDisk = legacyQuantityPtrToByteUnits(corev1.ResourceEphemeralStorage, pConf.ResourceDisk)
+		pConf.ResourceMemory = legacyQuantityPtrToByteUnits(corev1.ResourceMemory, pConf.ResourceMemory)
+		pConf.ResourceNetwork = legacyQuantityPtrToByteUnits(resourceCommon.ResourceNameNetwork, pConf.ResourceNetwork)
+	}
+
+	pConf.ResourcePool = resourcePoolFromPod(pod)
 
 	if mainContainer.TTY {
 		ttyEnabled := true
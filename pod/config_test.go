@@ -120,6 +120,7 @@ func TestParsePod(t *testing.T) {
 		AnnotationKeySecurityWorkloadMetadata:    "app-metadata",
 		AnnotationKeySecurityWorkloadMetadataSig: "app-metadata-sig",
 
+		AnnotationKeyAZ:               "us-east-1a",
 		AnnotationKeyPodHostnameStyle: "ec2",
 		AnnotationKeyPodSchedPolicy:   "batch",
 
@@ -169,6 +170,7 @@ func TestParsePod(t *testing.T) {
 	expConf := Config{
 		AppArmorProfile:          ptr.StringPtr("localhost/docker_titus"),
 		AccountID:                ptr.StringPtr("123456"),
+		AvailabilityZone:         ptr.StringPtr("us-east-1a"),
 		WorkloadDetail:           ptr.StringPtr("mydetail"),
 		WorkloadMetadata:         ptr.StringPtr("app-metadata"),
 		WorkloadMetadataSig:      ptr.StringPtr("app-metadata-sig"),
@@ -240,6 +242,12 @@ func TestParsePodInvalid(t *testing.T) {
 			},
 			errMatch: "pod.netflix.com/hostname-style annotation is not a valid hostname style: not-ec2",
 		},
+		{
+			annotations: map[string]string{
+				AnnotationKeyPodMainContainerName: "missing",
+			},
+			errMatch: "pod.netflix.com/main-container-name annotation is not the name of a container: missing",
+		},
 		{
 			annotations: map[string]string{
 				AnnotationKeyLogKeepLocalFile: "yes",
@@ -282,6 +290,18 @@ func TestParsePodInvalid(t *testing.T) {
 			},
 			errMatch: "pod.netflix.com/sched-policy annotation is not a valid scheduler policy: something",
 		},
+		{
+			annotations: map[string]string{
+				AnnotationKeySchedLatencyReq: "slow",
+			},
+			errMatch: "scheduler.titus.netflix.com/sched-latency-req annotation is not a valid scheduling latency: slow",
+		},
+		{
+			annotations: map[string]string{
+				AnnotationKeySchedSpreadingReq: "scatter",
+			},
+			errMatch: "scheduler.titus.netflix.com/spreading-req annotation is not a valid scheduling spreading: scatter",
+		},
 	}
 
 	for _, ann := range badAnnotations {
@@ -327,3 +347,26 @@ func TestLogUploadRegExp(t *testing.T) {
 	assert.Assert(t, conf.LogUploadRegExp != nil)
 	assert.Equal(t, conf.LogUploadRegExp.String(), ".*.foo")
 }
+
+func TestParsePodLegacyResourceNames(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{LabelKeyByteUnitsEnabled: "true"})
+	limits := pod.Spec.Containers[0].Resources.Limits
+	delete(limits, resourceCommon.ResourceNameGpu)
+	delete(limits, resourceCommon.ResourceNameNetwork)
+	limits[resourceCommon.ResourceNameNvidiaGpu] = resource.MustParse("2")
+	limits[resourceCommon.ResourceNameNetworkLegacy] = resource.MustParse("256M")
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, conf.ResourceGPU, stringToResourcePtr("2"))
+	assert.DeepEqual(t, conf.ResourceNetwork, stringToResourcePtr("256M"))
+}
+
+func TestParsePodConflictingResourceNames(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{LabelKeyByteUnitsEnabled: "true"})
+	pod.Spec.Containers[0].Resources.Limits[resourceCommon.ResourceNameGpuLegacy] = resource.MustParse("1")
+
+	_, err := PodToConfig(pod)
+	assert.ErrorContains(t, err, "container task-id-in-container has invalid resource limits: 1 error occurred")
+	assert.ErrorContains(t, err, "resource titus/gpu has conflicting values: gpu=1, titus/gpu=0")
+}
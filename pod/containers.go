@@ -1,32 +1,171 @@
 package pod
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 )
 
-func GetMainUserContainer(pod *corev1.Pod) *corev1.Container {
+// ContainerRole is the role of a container in a pod
+type ContainerRole string
+
+const (
+	// ContainerRoleMain is the role of the container running the user workload
+	ContainerRoleMain ContainerRole = "main"
+	// ContainerRolePlatformSidecar is the role of the containers added by Titus
+	ContainerRolePlatformSidecar ContainerRole = "platform-sidecar"
+	// ContainerRoleUserSidecar is the role of the other containers requested by the user
+	ContainerRoleUserSidecar ContainerRole = "user-sidecar"
+	// ContainerRoleInit is the role of init containers
+	ContainerRoleInit ContainerRole = "init"
+
+	mainContainerName = "main"
+)
+
+// ContainerClassification is the role of a container, with the reason it was given that role
+type ContainerClassification struct {
+	Name   string
+	Role   ContainerRole
+	Reason string
+}
+
+// ClassifyContainers returns the role of every container of the pod, init containers first, in the order of
+// the pod spec. The main container is the one GetMainUserContainer returns.
+func ClassifyContainers(pod *corev1.Pod) ([]ContainerClassification, error) {
+	return classifyContainers(pod, false)
+}
+
+// ClassifyContainersStrict is like ClassifyContainers, but returns an error if the main container is ambiguous:
+// the pod has no AnnotationKeyPodMainContainerName annotation, and either several containers match the naming
+// conventions, or none does and the pod has several containers that aren't platform sidecars.
+func ClassifyContainersStrict(pod *corev1.Pod) ([]ContainerClassification, error) {
+	return classifyContainers(pod, true)
+}
+
+func classifyContainers(pod *corev1.Pod, strict bool) ([]ContainerClassification, error) {
+	mainIdx, mainReason, err := mainContainerIndex(pod, strict)
+	if err != nil {
+		return nil, err
+	}
+
+	classifications := make([]ContainerClassification, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	for _, c := range pod.Spec.InitContainers {
+		classifications = append(classifications, ContainerClassification{
+			Name:   c.Name,
+			Role:   ContainerRoleInit,
+			Reason: "init container",
+		})
+	}
+
+	for i, c := range pod.Spec.Containers {
+		classification := ContainerClassification{Name: c.Name}
+		switch {
+		case i == mainIdx:
+			classification.Role = ContainerRoleMain
+			classification.Reason = mainReason
+		case IsPlatformSidecarContainer(c.Name, pod):
+			classification.Role = ContainerRolePlatformSidecar
+			classification.Reason = fmt.Sprintf("has the %s annotation", ContainerAnnotation(c.Name, AnnotationKeySuffixContainersSidecar))
+		default:
+			classification.Role = ContainerRoleUserSidecar
+			classification.Reason = "neither the main container nor a platform sidecar"
+		}
+		classifications = append(classifications, classification)
+	}
+
+	return classifications, nil
+}
+
+// mainContainerIndex returns the index of the main container, and the reason it was picked
+func mainContainerIndex(pod *corev1.Pod, strict bool) (int, string, error) {
 	if len(pod.Spec.Containers) == 0 {
-		return nil
+		return -1, "", errors.New("pod has no containers")
 	}
 
-	// Older method where the main container's name was the taskid
-	for i := range pod.Spec.Containers {
-		c := &pod.Spec.Containers[i]
-		if c.Name == pod.Name {
-			return c
+	name, ok := pod.Annotations[AnnotationKeyPodMainContainerName]
+	if !ok {
+		return guessMainContainerIndex(pod, strict)
+	}
+
+	for i, c := range pod.Spec.Containers {
+		if c.Name != name {
+			continue
 		}
+		if IsPlatformSidecarContainer(name, pod) {
+			return -1, "", fmt.Errorf("%s annotation is the name of a platform sidecar: %s", AnnotationKeyPodMainContainerName, name)
+		}
+		return i, fmt.Sprintf("named by the %s annotation", AnnotationKeyPodMainContainerName), nil
 	}
+	return -1, "", fmt.Errorf("%s annotation is not the name of a container: %s", AnnotationKeyPodMainContainerName, name)
+}
 
+func guessMainContainerIndex(pod *corev1.Pod, strict bool) (int, string, error) {
+	// Older method where the main container's name was the taskid
+	legacyIdx := containerIndex(pod, pod.Name)
 	// Newer method where the main container's name is "main"
+	mainIdx := containerIndex(pod, mainContainerName)
+
+	if strict && legacyIdx >= 0 && mainIdx >= 0 && legacyIdx != mainIdx {
+		return -1, "", fmt.Errorf("main container is ambiguous: both %s and %s could be the main container", pod.Name, mainContainerName)
+	}
+	if legacyIdx >= 0 {
+		return legacyIdx, "named after the pod", nil
+	}
+	if mainIdx >= 0 {
+		return mainIdx, fmt.Sprintf("named %q", mainContainerName), nil
+	}
+
+	// Fallback method, whatever came first, skipping platform sidecars
+	var candidates []string
+	candidateIdx := -1
+	for i, c := range pod.Spec.Containers {
+		if IsPlatformSidecarContainer(c.Name, pod) {
+			continue
+		}
+		if candidateIdx < 0 {
+			candidateIdx = i
+		}
+		candidates = append(candidates, c.Name)
+	}
+
+	switch {
+	case strict && len(candidates) > 1:
+		return -1, "", fmt.Errorf("main container is ambiguous: any of %s could be the main container", strings.Join(candidates, ", "))
+	case candidateIdx >= 0:
+		return candidateIdx, "first container that isn't a platform sidecar", nil
+	case strict:
+		return -1, "", errors.New("main container is ambiguous: all the containers are platform sidecars")
+	default:
+		return 0, "first container", nil
+	}
+}
+
+func containerIndex(pod *corev1.Pod, name string) int {
 	for i := range pod.Spec.Containers {
-		c := &pod.Spec.Containers[i]
-		if c.Name == "main" {
-			return c
+		if pod.Spec.Containers[i].Name == name {
+			return i
 		}
 	}
+	return -1
+}
 
-	// Fallback method, whatever came first
-	return &pod.Spec.Containers[0]
+// GetMainUserContainer returns the main container of the pod, the same one ClassifyContainers reports: the
+// container named by the AnnotationKeyPodMainContainerName annotation, or else the container named after the
+// pod, or else the container named "main", or else the first container that isn't a platform sidecar. Pods
+// without platform sidecars get the first container, as before platform sidecars were taken into account.
+// Use ClassifyContainersStrict to detect pods where the main container is ambiguous.
+func GetMainUserContainer(pod *corev1.Pod) *corev1.Container {
+	idx, _, err := mainContainerIndex(pod, false)
+	if err != nil {
+		if len(pod.Spec.Containers) == 0 {
+			return nil
+		}
+		// The annotation doesn't name a valid main container, fall back to guessing
+		idx, _, _ = guessMainContainerIndex(pod, false)
+	}
+	return &pod.Spec.Containers[idx]
 }
 
 func GetContainerByName(pod *corev1.Pod, name string) *corev1.Container {
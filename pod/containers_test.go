@@ -0,0 +1,91 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func buildMultiContainerPod(annotations map[string]string, names ...string) *corev1.Pod {
+	pod := buildPod(annotations, map[string]string{})
+	pod.Spec.Containers = nil
+	for _, name := range names {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: name})
+	}
+	return pod
+}
+
+func TestClassifyContainers(t *testing.T) {
+	pod := buildMultiContainerPod(map[string]string{
+		ContainerAnnotation("logviewer", AnnotationKeySuffixContainersSidecar): "logviewer",
+	}, "logviewer", "main", "envoy")
+	pod.Spec.InitContainers = []corev1.Container{{Name: "setup"}}
+
+	classifications, err := ClassifyContainersStrict(pod)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, classifications, []ContainerClassification{
+		{Name: "setup", Role: ContainerRoleInit, Reason: "init container"},
+		{Name: "logviewer", Role: ContainerRolePlatformSidecar, Reason: "has the logviewer.containers.netflix.com/platform-sidecar annotation"},
+		{Name: "main", Role: ContainerRoleMain, Reason: `named "main"`},
+		{Name: "envoy", Role: ContainerRoleUserSidecar, Reason: "neither the main container nor a platform sidecar"},
+	})
+	assert.Equal(t, GetMainUserContainer(pod).Name, "main")
+}
+
+func TestClassifyContainersAmbiguous(t *testing.T) {
+	sidecar := ContainerAnnotation("logviewer", AnnotationKeySuffixContainersSidecar)
+
+	// The first container that isn't a platform sidecar is picked, unless in strict mode
+	pod := buildMultiContainerPod(map[string]string{sidecar: "logviewer"}, "logviewer", "app", "envoy")
+	classifications, err := ClassifyContainers(pod)
+	assert.NilError(t, err)
+	assert.Equal(t, classifications[1].Role, ContainerRoleMain)
+	assert.Equal(t, classifications[1].Reason, "first container that isn't a platform sidecar")
+	assert.Equal(t, GetMainUserContainer(pod).Name, "app")
+	_, err = ClassifyContainersStrict(pod)
+	assert.ErrorContains(t, err, "main container is ambiguous: any of app, envoy could be the main container")
+
+	// Without platform sidecars, the first container is picked as it always was
+	pod = buildMultiContainerPod(nil, "app", "envoy")
+	classifications, err = ClassifyContainers(pod)
+	assert.NilError(t, err)
+	assert.Equal(t, classifications[0].Role, ContainerRoleMain)
+	assert.Equal(t, GetMainUserContainer(pod).Name, "app")
+
+	pod = buildMultiContainerPod(nil, "foo", "main")
+	_, err = ClassifyContainersStrict(pod)
+	assert.ErrorContains(t, err, "main container is ambiguous: both foo and main could be the main container")
+
+	pod = buildMultiContainerPod(map[string]string{sidecar: "logviewer"}, "logviewer")
+	_, err = ClassifyContainersStrict(pod)
+	assert.ErrorContains(t, err, "main container is ambiguous: all the containers are platform sidecars")
+
+	// The annotation resolves the ambiguity
+	pod = buildMultiContainerPod(map[string]string{sidecar: "logviewer", AnnotationKeyPodMainContainerName: "envoy"}, "logviewer", "app", "envoy")
+	classifications, err = ClassifyContainersStrict(pod)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, classifications[2], ContainerClassification{
+		Name:   "envoy",
+		Role:   ContainerRoleMain,
+		Reason: "named by the pod.netflix.com/main-container-name annotation",
+	})
+	assert.Equal(t, GetMainUserContainer(pod).Name, "envoy")
+}
+
+func TestClassifyContainersInvalidAnnotation(t *testing.T) {
+	sidecar := ContainerAnnotation("logviewer", AnnotationKeySuffixContainersSidecar)
+
+	pod := buildMultiContainerPod(map[string]string{AnnotationKeyPodMainContainerName: "missing"}, "app")
+	_, err := ClassifyContainers(pod)
+	assert.ErrorContains(t, err, "pod.netflix.com/main-container-name annotation is not the name of a container: missing")
+	assert.Equal(t, GetMainUserContainer(pod).Name, "app")
+
+	pod = buildMultiContainerPod(map[string]string{sidecar: "logviewer", AnnotationKeyPodMainContainerName: "logviewer"}, "logviewer", "app")
+	_, err = ClassifyContainers(pod)
+	assert.ErrorContains(t, err, "pod.netflix.com/main-container-name annotation is the name of a platform sidecar: logviewer")
+
+	_, err = ClassifyContainers(buildMultiContainerPod(nil))
+	assert.ErrorContains(t, err, "pod has no containers")
+	assert.Assert(t, GetMainUserContainer(buildMultiContainerPod(nil)) == nil)
+}
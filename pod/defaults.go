@@ -0,0 +1,119 @@
+package pod
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodSchemaVersionCurrent is the schema version of the v1 pod spec
+const PodSchemaVersionCurrent uint32 = 1
+
+// Defaults describes the values that are filled into a pod when they are missing
+type Defaults struct {
+	// PodSchemaVersion is set on pods that don't have a schema version annotation
+	PodSchemaVersion uint32
+	// Tolerations are added to the pod unless an identical toleration is already present
+	Tolerations []corev1.Toleration
+}
+
+// StandardDefaults returns the defaults applied to every Titus pod
+func StandardDefaults() Defaults {
+	return Defaults{
+		PodSchemaVersion: PodSchemaVersionCurrent,
+		Tolerations:      StandardTolerations(),
+	}
+}
+
+// StandardTolerations returns the tolerations every Titus pod carries. Titus manages the
+// lifecycle of pods on unhealthy nodes itself, so pods tolerate the not-ready and unreachable
+// taints indefinitely instead of being evicted by the taint manager.
+func StandardTolerations() []corev1.Toleration {
+	return []corev1.Toleration{
+		{
+			Key:      corev1.TaintNodeNotReady,
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoExecute,
+		},
+		{
+			Key:      corev1.TaintNodeUnreachable,
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoExecute,
+		},
+	}
+}
+
+// ApplyDefaults applies the standard defaults to a pod. See Defaults.Apply.
+func ApplyDefaults(pod *corev1.Pod) {
+	StandardDefaults().Apply(pod)
+}
+
+// Apply modifies the pod in place, filling in:
+//   - the pod schema version annotation, if missing
+//   - a normalized (trimmed and sorted) list of security groups and subnets
+//   - the egress and ingress bandwidth annotations from the main container's network resource, if missing
+//   - the default tolerations
+func (d Defaults) Apply(pod *corev1.Pod) {
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	annotations := pod.Annotations
+
+	if _, ok := annotations[AnnotationKeyPodSchemaVersion]; !ok {
+		annotations[AnnotationKeyPodSchemaVersion] = strconv.FormatUint(uint64(d.PodSchemaVersion), 10)
+	}
+
+	for _, key := range []string{AnnotationKeyNetworkSecurityGroups, AnnotationKeyNetworkSubnetIDs} {
+		if val, ok := annotations[key]; ok {
+			annotations[key] = normalizeList(val)
+		}
+	}
+
+	if mainContainer := GetMainUserContainer(pod); mainContainer != nil {
+		// Conflicting resource names are reported by PodToConfig, use the best-effort normalized value here
+		limits, _ := resourceCommon.Normalize(mainContainer.Resources.Limits)
+		network, ok := limits[resourceCommon.ResourceNameNetwork]
+		if byteUnits, err := ByteUnitsEnabled(pod); err == nil && !byteUnits {
+			network = legacyQuantityToByteUnits(resourceCommon.ResourceNameNetwork, network)
+		}
+		if ok && !network.IsZero() {
+			for _, key := range []string{AnnotationKeyEgressBandwidth, AnnotationKeyIngressBandwidth} {
+				if _, ok := annotations[key]; !ok {
+					annotations[key] = network.String()
+				}
+			}
+		}
+	}
+
+	for _, toleration := range d.Tolerations {
+		if !hasToleration(pod.Spec.Tolerations, toleration) {
+			pod.Spec.Tolerations = append(pod.Spec.Tolerations, toleration)
+		}
+	}
+}
+
+// normalizeList trims whitespace from each element of a comma-separated list, drops empty
+// elements and sorts the result
+func normalizeList(val string) string {
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+func hasToleration(tolerations []corev1.Toleration, toleration corev1.Toleration) bool {
+	for i := range tolerations {
+		if tolerations[i].MatchToleration(&toleration) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package pod
+
+import (
+	"testing"
+
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyNetworkSecurityGroups: " sg-2, sg-1 ,",
+		AnnotationKeyNetworkSubnetIDs:      "subnet-b,subnet-a",
+	}, map[string]string{LabelKeyByteUnitsEnabled: "true"})
+
+	ApplyDefaults(pod)
+
+	assert.Equal(t, pod.Annotations[AnnotationKeyPodSchemaVersion], "1")
+	assert.Equal(t, pod.Annotations[AnnotationKeyNetworkSecurityGroups], "sg-1,sg-2")
+	assert.Equal(t, pod.Annotations[AnnotationKeyNetworkSubnetIDs], "subnet-a,subnet-b")
+	assert.Equal(t, pod.Annotations[AnnotationKeyEgressBandwidth], "128M")
+	assert.Equal(t, pod.Annotations[AnnotationKeyIngressBandwidth], "128M")
+	assert.DeepEqual(t, pod.Spec.Tolerations, StandardTolerations())
+}
+
+func TestApplyDefaultsPreservesExistingValues(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyPodSchemaVersion: "2",
+		AnnotationKeyEgressBandwidth:  "10M",
+	}, map[string]string{LabelKeyByteUnitsEnabled: "true"})
+	pod.Spec.Tolerations = []corev1.Toleration{
+		{
+			Key:      corev1.TaintNodeNotReady,
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoExecute,
+		},
+	}
+
+	ApplyDefaults(pod)
+	// Applying defaults twice should be a no-op
+	ApplyDefaults(pod)
+
+	assert.Equal(t, pod.Annotations[AnnotationKeyPodSchemaVersion], "2")
+	assert.Equal(t, pod.Annotations[AnnotationKeyEgressBandwidth], "10M")
+	assert.Equal(t, pod.Annotations[AnnotationKeyIngressBandwidth], "128M")
+	_, ok := pod.Annotations[AnnotationKeyNetworkSecurityGroups]
+	assert.Assert(t, !ok)
+	assert.Equal(t, len(pod.Spec.Tolerations), 2)
+}
+
+func TestApplyDefaultsLegacyUnits(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{})
+	pod.Spec.Containers[0].Resources.Limits[resourceCommon.ResourceNameNetwork] = resource.MustParse("128")
+
+	ApplyDefaults(pod)
+
+	assert.Equal(t, pod.Annotations[AnnotationKeyEgressBandwidth], "128M")
+	assert.Equal(t, pod.Annotations[AnnotationKeyIngressBandwidth], "128M")
+}
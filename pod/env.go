@@ -0,0 +1,146 @@
+package pod
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RedactedEnvValue replaces the values of the environment variables hidden by RedactEnv
+const RedactedEnvValue = "<redacted>"
+
+// ReservedEnvVarPrefixes are the prefixes of the environment variable names that only Titus may set
+var ReservedEnvVarPrefixes = []string{"TITUS_"}
+
+// ClassifiedEnv is the environment of the main container, partitioned by where the variables come from.
+// Variables keep the order of the container spec.
+type ClassifiedEnv struct {
+	// System variables are set by Titus, see AnnotationKeyPodTitusSystemEnvVarNames
+	System []corev1.EnvVar
+	// Injected variables are set by external mutators, see AnnotationKeyPodInjectedEnvVarNames
+	Injected []corev1.EnvVar
+	// User variables come from the job spec
+	User []corev1.EnvVar
+}
+
+// envVarNames returns the environment variable names listed in an annotation, or nil if the pod doesn't have it
+func envVarNames(annotations map[string]string, key string) []string {
+	val, ok := annotations[key]
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	for _, name := range strings.Split(strings.TrimSpace(val), ",") {
+		names = append(names, strings.TrimSpace(name))
+	}
+	return names
+}
+
+func envVarNameSet(annotations map[string]string, key string) map[string]bool {
+	set := map[string]bool{}
+	for _, name := range envVarNames(annotations, key) {
+		set[name] = true
+	}
+	return set
+}
+
+// ClassifyEnv returns the environment of the main container of the pod, partitioned into system, injected
+// and user variables. A variable listed as both system and injected is a system variable.
+func ClassifyEnv(pod *corev1.Pod) (*ClassifiedEnv, error) {
+	mainContainer := GetMainUserContainer(pod)
+	if mainContainer == nil {
+		return nil, errors.New("could not find main container in pod")
+	}
+
+	systemNames := envVarNameSet(pod.Annotations, AnnotationKeyPodTitusSystemEnvVarNames)
+	injectedNames := envVarNameSet(pod.Annotations, AnnotationKeyPodInjectedEnvVarNames)
+
+	env := &ClassifiedEnv{}
+	for _, e := range mainContainer.Env {
+		switch {
+		case systemNames[e.Name]:
+			env.System = append(env.System, e)
+		case injectedNames[e.Name]:
+			env.Injected = append(env.Injected, e)
+		default:
+			env.User = append(env.User, e)
+		}
+	}
+	return env, nil
+}
+
+// RedactEnv returns a copy of the environment variables with their values replaced by RedactedEnvValue.
+// References to other objects (ValueFrom) are kept, since they don't hold the values themselves.
+func RedactEnv(env []corev1.EnvVar) []corev1.EnvVar {
+	if env == nil {
+		return nil
+	}
+
+	redacted := make([]corev1.EnvVar, len(env))
+	for i, e := range env {
+		redacted[i] = e
+		if e.Value != "" {
+			redacted[i].Value = RedactedEnvValue
+		}
+	}
+	return redacted
+}
+
+// Redacted returns a copy of the environment that is safe to log: the values of the user and injected
+// variables, which may hold secrets, are redacted. System variables are kept as is.
+func (e *ClassifiedEnv) Redacted() *ClassifiedEnv {
+	return &ClassifiedEnv{
+		System:   append([]corev1.EnvVar(nil), e.System...),
+		Injected: RedactEnv(e.Injected),
+		User:     RedactEnv(e.User),
+	}
+}
+
+// ValidateEnv returns an error if user variables of the main container collide with names reserved for the
+// system: a system variable that is set more than once (so the user value would shadow it, or be shadowed),
+// a user variable with one of the ReservedEnvVarPrefixes, or a name listed as both system and injected.
+func ValidateEnv(pod *corev1.Pod) error {
+	env, err := ClassifyEnv(pod)
+	if err != nil {
+		return err
+	}
+
+	var result *multierror.Error
+	containerName := GetMainUserContainer(pod).Name
+
+	counts := map[string]int{}
+	for _, e := range env.System {
+		counts[e.Name]++
+	}
+	for _, e := range env.System {
+		if counts[e.Name] > 1 {
+			result = multierror.Append(result, fmt.Errorf("environment variable %s is reserved for the system, but is set %d times in container %s",
+				e.Name, counts[e.Name], containerName))
+			// Only report each name once
+			counts[e.Name] = 0
+		}
+	}
+
+	for _, e := range env.User {
+		for _, prefix := range ReservedEnvVarPrefixes {
+			if strings.HasPrefix(e.Name, prefix) {
+				result = multierror.Append(result, fmt.Errorf("environment variable %s of container %s uses the reserved prefix %s",
+					e.Name, containerName, prefix))
+			}
+		}
+	}
+
+	injectedNames := envVarNameSet(pod.Annotations, AnnotationKeyPodInjectedEnvVarNames)
+	for _, name := range envVarNames(pod.Annotations, AnnotationKeyPodTitusSystemEnvVarNames) {
+		if injectedNames[name] {
+			result = multierror.Append(result, fmt.Errorf("environment variable %s is listed in both the %s and %s annotations",
+				name, AnnotationKeyPodTitusSystemEnvVarNames, AnnotationKeyPodInjectedEnvVarNames))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
@@ -0,0 +1,74 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func buildEnvPod(system, injected string, env ...corev1.EnvVar) *corev1.Pod {
+	pod := buildPod(map[string]string{
+		AnnotationKeyPodTitusSystemEnvVarNames: system,
+		AnnotationKeyPodInjectedEnvVarNames:    injected,
+	}, map[string]string{})
+	pod.Spec.Containers[0].Env = env
+	return pod
+}
+
+func TestClassifyEnv(t *testing.T) {
+	secretRef := &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"},
+	}
+	pod := buildEnvPod("TITUS_TASK_ID, EC2_REGION", "SIDECAR_TOKEN",
+		corev1.EnvVar{Name: "TITUS_TASK_ID", Value: "task-id"},
+		corev1.EnvVar{Name: "DB_PASSWORD", ValueFrom: secretRef},
+		corev1.EnvVar{Name: "SIDECAR_TOKEN", Value: "token"},
+		corev1.EnvVar{Name: "EC2_REGION", Value: "us-east-1"},
+		corev1.EnvVar{Name: "API_KEY", Value: "hunter2"},
+	)
+
+	env, err := ClassifyEnv(pod)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, env, &ClassifiedEnv{
+		System: []corev1.EnvVar{
+			{Name: "TITUS_TASK_ID", Value: "task-id"},
+			{Name: "EC2_REGION", Value: "us-east-1"},
+		},
+		Injected: []corev1.EnvVar{{Name: "SIDECAR_TOKEN", Value: "token"}},
+		User: []corev1.EnvVar{
+			{Name: "DB_PASSWORD", ValueFrom: secretRef},
+			{Name: "API_KEY", Value: "hunter2"},
+		},
+	})
+
+	redacted := env.Redacted()
+	assert.DeepEqual(t, redacted, &ClassifiedEnv{
+		System: []corev1.EnvVar{
+			{Name: "TITUS_TASK_ID", Value: "task-id"},
+			{Name: "EC2_REGION", Value: "us-east-1"},
+		},
+		Injected: []corev1.EnvVar{{Name: "SIDECAR_TOKEN", Value: RedactedEnvValue}},
+		User: []corev1.EnvVar{
+			{Name: "DB_PASSWORD", ValueFrom: secretRef},
+			{Name: "API_KEY", Value: RedactedEnvValue},
+		},
+	})
+	// The original environment isn't modified
+	assert.Equal(t, env.User[1].Value, "hunter2")
+
+	assert.NilError(t, ValidateEnv(pod))
+}
+
+func TestValidateEnv(t *testing.T) {
+	pod := buildEnvPod("TITUS_TASK_ID,EC2_REGION", "EC2_REGION",
+		corev1.EnvVar{Name: "TITUS_TASK_ID", Value: "task-id"},
+		corev1.EnvVar{Name: "TITUS_TASK_ID", Value: "not-the-task-id"},
+		corev1.EnvVar{Name: "TITUS_DEBUG", Value: "true"},
+	)
+
+	err := ValidateEnv(pod)
+	assert.ErrorContains(t, err, "environment variable TITUS_TASK_ID is reserved for the system, but is set 2 times in container task-id-in-container")
+	assert.ErrorContains(t, err, "environment variable TITUS_DEBUG of container task-id-in-container uses the reserved prefix TITUS_")
+	assert.ErrorContains(t, err, "environment variable EC2_REGION is listed in both the pod.titus.netflix.com/system-env-var-names and pod.titus.netflix.com/injected-env-var-names annotations")
+}
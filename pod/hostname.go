@@ -0,0 +1,120 @@
+package pod
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// HostnameStyleDefault names containers after their task ID
+	HostnameStyleDefault = ""
+	// HostnameStyleEC2 names containers like EC2 instances, ip-a-b-c-d, after their IP address
+	HostnameStyleEC2 = "ec2"
+)
+
+// HostnameFunc computes the hostname of a pod. The allocation is the network assignment of the pod, which can
+// be nil if the pod doesn't have one yet.
+type HostnameFunc func(pod *corev1.Pod, cfg *Config, allocation *NetworkAllocation) (string, error)
+
+var (
+	hostnameStylesLock sync.RWMutex
+	hostnameStyles     = map[string]HostnameFunc{
+		HostnameStyleDefault: taskIDHostname,
+		HostnameStyleEC2:     ec2Hostname,
+	}
+)
+
+// RegisterHostnameStyle adds a hostname style, which becomes a valid value of AnnotationKeyPodHostnameStyle
+func RegisterHostnameStyle(style string, fn HostnameFunc) error {
+	hostnameStylesLock.Lock()
+	defer hostnameStylesLock.Unlock()
+
+	if _, ok := hostnameStyles[style]; ok {
+		return fmt.Errorf("hostname style %q is already registered", style)
+	}
+	hostnameStyles[style] = fn
+	return nil
+}
+
+// IsValidHostnameStyle returns true if the hostname style is registered
+func IsValidHostnameStyle(style string) bool {
+	hostnameStylesLock.RLock()
+	defer hostnameStylesLock.RUnlock()
+
+	_, ok := hostnameStyles[style]
+	return ok
+}
+
+// HostnameStyles returns the registered hostname styles, sorted
+func HostnameStyles() []string {
+	hostnameStylesLock.RLock()
+	defer hostnameStylesLock.RUnlock()
+
+	styles := make([]string, 0, len(hostnameStyles))
+	for style := range hostnameStyles {
+		styles = append(styles, style)
+	}
+	sort.Strings(styles)
+	return styles
+}
+
+// Hostname returns the hostname of the container of a pod, following its hostname style (see
+// AnnotationKeyPodHostnameStyle). The hostname is always a valid DNS label.
+func Hostname(pod *corev1.Pod, cfg *Config, allocation *NetworkAllocation) (string, error) {
+	style := HostnameStyleDefault
+	if cfg.HostnameStyle != nil {
+		style = *cfg.HostnameStyle
+	}
+
+	hostnameStylesLock.RLock()
+	fn, ok := hostnameStyles[style]
+	hostnameStylesLock.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%s annotation is not a valid hostname style: %s", AnnotationKeyPodHostnameStyle, style)
+	}
+
+	hostname, err := fn(pod, cfg, allocation)
+	if err != nil {
+		return "", err
+	}
+	if errs := validation.IsDNS1123Label(hostname); len(errs) > 0 {
+		return "", fmt.Errorf("hostname %s is not a valid DNS label: %s", hostname, strings.Join(errs, ", "))
+	}
+	return hostname, nil
+}
+
+func taskIDHostname(pod *corev1.Pod, cfg *Config, allocation *NetworkAllocation) (string, error) {
+	if cfg.TaskID != nil && *cfg.TaskID != "" {
+		return strings.ToLower(*cfg.TaskID), nil
+	}
+	if pod.Name != "" {
+		return strings.ToLower(pod.Name), nil
+	}
+	return "", errors.New("pod has no task ID")
+}
+
+// ec2Hostname uses the IPv4 address when the pod has one, and the IPv6 address otherwise. IPv6 addresses are
+// written in full, with each group of the address separated by a dash.
+func ec2Hostname(pod *corev1.Pod, cfg *Config, allocation *NetworkAllocation) (string, error) {
+	if allocation != nil && allocation.IPv4Address != nil {
+		ip := allocation.IPv4Address.To4()
+		return fmt.Sprintf("ip-%d-%d-%d-%d", ip[0], ip[1], ip[2], ip[3]), nil
+	}
+
+	if allocation != nil && allocation.IPv6Address != nil {
+		ip := allocation.IPv6Address.To16()
+		groups := make([]string, 0, 8)
+		for i := 0; i < len(ip); i += 2 {
+			groups = append(groups, fmt.Sprintf("%02x%02x", ip[i], ip[i+1]))
+		}
+		return "ip-" + strings.Join(groups, "-"), nil
+	}
+
+	return "", fmt.Errorf("pod has no IP address to compute a %s style hostname from", HostnameStyleEC2)
+}
@@ -0,0 +1,67 @@
+package pod
+
+import (
+	"net"
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestHostname(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{})
+	ipv4 := &NetworkAllocation{IPv4Address: net.ParseIP("100.66.1.2"), IPv6Address: net.ParseIP("2600:1f18::1")}
+	ipv6 := &NetworkAllocation{IPv6Address: net.ParseIP("2600:1f18:4a3:6901::1")}
+
+	tests := []struct {
+		cfg        *Config
+		allocation *NetworkAllocation
+		expected   string
+	}{
+		{cfg: &Config{TaskID: ptr.StringPtr("7c5b6d5e-ea1b-4c3f-8c12-dbd1a8a1e3b0")}, expected: "7c5b6d5e-ea1b-4c3f-8c12-dbd1a8a1e3b0"},
+		{cfg: &Config{}, expected: "foo"},
+		{cfg: &Config{HostnameStyle: ptr.StringPtr("ec2")}, allocation: ipv4, expected: "ip-100-66-1-2"},
+		{cfg: &Config{HostnameStyle: ptr.StringPtr("ec2")}, allocation: ipv6, expected: "ip-2600-1f18-04a3-6901-0000-0000-0000-0001"},
+	}
+
+	for _, tt := range tests {
+		hostname, err := Hostname(pod, tt.cfg, tt.allocation)
+		assert.NilError(t, err)
+		assert.Equal(t, hostname, tt.expected)
+	}
+
+	_, err := Hostname(pod, &Config{HostnameStyle: ptr.StringPtr("ec2")}, nil)
+	assert.ErrorContains(t, err, "pod has no IP address to compute a ec2 style hostname from")
+
+	_, err = Hostname(pod, &Config{TaskID: ptr.StringPtr("task_1")}, nil)
+	assert.ErrorContains(t, err, "hostname task_1 is not a valid DNS label")
+
+	_, err = Hostname(pod, &Config{HostnameStyle: ptr.StringPtr("unknown")}, nil)
+	assert.ErrorContains(t, err, "pod.netflix.com/hostname-style annotation is not a valid hostname style: unknown")
+}
+
+func TestRegisterHostnameStyle(t *testing.T) {
+	assert.ErrorContains(t, RegisterHostnameStyle(HostnameStyleEC2, ec2Hostname), `hostname style "ec2" is already registered`)
+
+	pod := buildPod(map[string]string{AnnotationKeyPodHostnameStyle: "job"}, map[string]string{})
+	_, err := PodToConfig(pod)
+	assert.ErrorContains(t, err, "pod.netflix.com/hostname-style annotation is not a valid hostname style: job")
+
+	t.Cleanup(func() {
+		hostnameStylesLock.Lock()
+		defer hostnameStylesLock.Unlock()
+		delete(hostnameStyles, "job")
+	})
+	assert.NilError(t, RegisterHostnameStyle("job", func(pod *corev1.Pod, cfg *Config, allocation *NetworkAllocation) (string, error) {
+		return "job-" + *cfg.JobID, nil
+	}))
+	assert.DeepEqual(t, HostnameStyles(), []string{"", "ec2", "job"})
+
+	pod.Annotations[AnnotationKeyJobID] = "myjobid"
+	cfg, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	hostname, err := Hostname(pod, cfg, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, hostname, "job-myjobid")
+}
@@ -0,0 +1,120 @@
+package pod
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	imageTagRegexp        = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	imageDigestRegexp     = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
+	imageRepositoryRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+)
+
+// ImageReference is a container image reference, split into its parts. Titus resolves image tags
+// to digests before creating pods, so the tag is usually only known from the image tag annotation.
+type ImageReference struct {
+	// Registry is the registry host, with an optional port. It is empty for images of the default registry.
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImageReference parses an image reference such as "registry.example.com:7002/titusops/alpine:latest@sha256:..."
+func ParseImageReference(image string) (*ImageReference, error) {
+	ref := &ImageReference{}
+	name := image
+
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+		if !imageDigestRegexp.MatchString(ref.Digest) {
+			return nil, fmt.Errorf("image %s has an invalid digest: %s", image, ref.Digest)
+		}
+	}
+
+	// A colon after the last slash separates the tag. A colon before it is part of the registry host.
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+		if !imageTagRegexp.MatchString(ref.Tag) {
+			return nil, fmt.Errorf("image %s has an invalid tag: %s", image, ref.Tag)
+		}
+	}
+
+	// The first component is a registry if it looks like a host name
+	if i := strings.Index(name, "/"); i >= 0 {
+		first := name[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			ref.Registry = first
+			name = name[i+1:]
+		}
+	}
+
+	ref.Repository = name
+	if !imageRepositoryRegexp.MatchString(ref.Repository) {
+		return nil, fmt.Errorf("image %s has an invalid repository: %s", image, ref.Repository)
+	}
+
+	return ref, nil
+}
+
+// Name returns the registry and repository of the image, without its tag or digest
+func (r *ImageReference) Name() string {
+	if r.Registry == "" {
+		return r.Repository
+	}
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the image reference in the "repo:tag@digest" form, omitting the parts that are unknown
+func (r *ImageReference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// GetImageReference returns the image reference of a container, with the tag from the image tag annotation
+// (see GetImageTagForContainer) when the image in the container spec doesn't have one
+func GetImageReference(cName string, pod *corev1.Pod) (*ImageReference, error) {
+	c := GetContainerByName(pod, cName)
+	if c == nil {
+		return nil, fmt.Errorf("pod %s has no container named %s", pod.Name, cName)
+	}
+
+	ref, err := ParseImageReference(c.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.Tag == "" {
+		if tag, ok := GetImageTagForContainer(cName, pod); ok && tag != "" {
+			if !imageTagRegexp.MatchString(tag) {
+				return nil, fmt.Errorf("image tag annotation of container %s is not a valid tag: %s", cName, tag)
+			}
+			ref.Tag = tag
+		}
+	}
+
+	return ref, nil
+}
+
+// SetImageTagForContainer records the original tag of the image of a container, using the
+// AnnotationKeySuffixContainerImageTag annotation. The deprecated AnnotationKeyImageTagPrefix annotation
+// is removed, so it can't shadow the new value.
+func SetImageTagForContainer(cName string, pod *corev1.Pod, tag string) {
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	delete(pod.Annotations, AnnotationKeyImageTagPrefix+cName)
+	pod.Annotations[ContainerAnnotation(cName, AnnotationKeySuffixContainerImageTag)] = tag
+}
@@ -0,0 +1,84 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const testDigest = "sha256:3fc9b689459d738f8c88a3a48aa9e33542016b7a4052e001aaa536fca74813cb"
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected ImageReference
+	}{
+		{
+			image:    "alpine",
+			expected: ImageReference{Repository: "alpine"},
+		},
+		{
+			image:    "titusops/alpine:latest",
+			expected: ImageReference{Repository: "titusops/alpine", Tag: "latest"},
+		},
+		{
+			image:    "registry.example.com:7002/titusops/alpine@" + testDigest,
+			expected: ImageReference{Registry: "registry.example.com:7002", Repository: "titusops/alpine", Digest: testDigest},
+		},
+		{
+			image:    "localhost/alpine:3.16@" + testDigest,
+			expected: ImageReference{Registry: "localhost", Repository: "alpine", Tag: "3.16", Digest: testDigest},
+		},
+	}
+
+	for _, tt := range tests {
+		ref, err := ParseImageReference(tt.image)
+		assert.NilError(t, err, tt.image)
+		assert.DeepEqual(t, *ref, tt.expected)
+		assert.Equal(t, ref.String(), tt.image)
+	}
+}
+
+func TestParseImageReferenceInvalid(t *testing.T) {
+	tests := []struct {
+		image    string
+		errMatch string
+	}{
+		{image: "alpine@sha256:abc", errMatch: "image alpine@sha256:abc has an invalid digest: sha256:abc"},
+		{image: "alpine:-latest", errMatch: "image alpine:-latest has an invalid tag: -latest"},
+		{image: "Titusops/alpine", errMatch: "image Titusops/alpine has an invalid repository: Titusops/alpine"},
+		{image: "", errMatch: "has an invalid repository"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseImageReference(tt.image)
+		assert.ErrorContains(t, err, tt.errMatch)
+	}
+}
+
+func TestGetImageReference(t *testing.T) {
+	cName := "task-id-in-container"
+	pod := buildPod(map[string]string{AnnotationKeyImageTagPrefix + cName: "v1"}, map[string]string{})
+	pod.Spec.Containers[0].Image = "my-registry.example.com/sample/helloworld@" + testDigest
+
+	ref, err := GetImageReference(cName, pod)
+	assert.NilError(t, err)
+	assert.Equal(t, ref.String(), "my-registry.example.com/sample/helloworld:v1@"+testDigest)
+
+	SetImageTagForContainer(cName, pod, "v2")
+	_, ok := pod.Annotations[AnnotationKeyImageTagPrefix+cName]
+	assert.Assert(t, !ok)
+	assert.Equal(t, pod.Annotations[ContainerAnnotation(cName, AnnotationKeySuffixContainerImageTag)], "v2")
+	ref, err = GetImageReference(cName, pod)
+	assert.NilError(t, err)
+	assert.Equal(t, ref.Tag, "v2")
+
+	// A tag in the container spec wins over the annotation
+	pod.Spec.Containers[0].Image = "my-registry.example.com/sample/helloworld:latest"
+	ref, err = GetImageReference(cName, pod)
+	assert.NilError(t, err)
+	assert.Equal(t, ref.Tag, "latest")
+
+	_, err = GetImageReference("sidecar", pod)
+	assert.ErrorContains(t, err, "pod foo has no container named sidecar")
+}
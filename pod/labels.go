@@ -46,3 +46,13 @@ func parseLabels(pod *corev1.Pod, pConf *Config) error {
 
 	return nil
 }
+
+// IsTitusPod returns true if the pod runs a Titus task, as opposed to daemonset pods and other pods
+// scheduled on Titus nodes
+func IsTitusPod(pod *corev1.Pod) bool {
+	if _, ok := pod.Labels[LabelKeyTaskId]; ok {
+		return true
+	}
+	_, ok := pod.Annotations[AnnotationKeyJobID]
+	return ok
+}
@@ -0,0 +1,150 @@
+package pod
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	// DefaultLogUploadCheckInterval is how often log files are checked for upload
+	DefaultLogUploadCheckInterval = 15 * time.Minute
+	// DefaultLogUploadThresholdTime is how long a log file has to be left unmodified before it is uploaded
+	DefaultLogUploadThresholdTime = 6 * time.Hour
+	// DefaultLogStdioCheckInterval is how often stdout and stderr are checked for rotation
+	DefaultLogStdioCheckInterval = 1 * time.Minute
+)
+
+var s3BucketNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// LogConfig is the log upload configuration of a pod, with the platform defaults filled in
+type LogConfig struct {
+	KeepLocalFile       bool
+	UploadCheckInterval time.Duration
+	UploadThresholdTime time.Duration
+	StdioCheckInterval  time.Duration
+	// UploadRegexp selects the files to upload, nil to upload every file
+	UploadRegexp *regexp.Regexp
+	// S3WriterIAMRole is the role assumed to upload the logs, empty to use the platform role
+	S3WriterIAMRole string
+	// S3BucketName is the bucket the logs are uploaded to, empty to use the platform bucket
+	S3BucketName string
+	// S3PathPrefix is the prefix of the keys of the job's logs, empty to use the workload name, see S3KeyPrefix
+	S3PathPrefix string
+}
+
+// NewLogConfig returns the log upload configuration of a pod config, using the defaults for the unset fields
+func NewLogConfig(cfg *Config) *LogConfig {
+	c := &LogConfig{
+		UploadCheckInterval: DefaultLogUploadCheckInterval,
+		UploadThresholdTime: DefaultLogUploadThresholdTime,
+		StdioCheckInterval:  DefaultLogStdioCheckInterval,
+		UploadRegexp:        cfg.LogUploadRegExp,
+	}
+
+	if cfg.LogKeepLocalFile != nil {
+		c.KeepLocalFile = *cfg.LogKeepLocalFile
+	}
+	if cfg.LogUploadCheckInterval != nil {
+		c.UploadCheckInterval = *cfg.LogUploadCheckInterval
+	}
+	if cfg.LogUploadThresholdTime != nil {
+		c.UploadThresholdTime = *cfg.LogUploadThresholdTime
+	}
+	if cfg.LogStdioCheckInterval != nil {
+		c.StdioCheckInterval = *cfg.LogStdioCheckInterval
+	}
+	if cfg.LogS3WriterIAMRole != nil {
+		c.S3WriterIAMRole = *cfg.LogS3WriterIAMRole
+	}
+	if cfg.LogS3BucketName != nil {
+		c.S3BucketName = *cfg.LogS3BucketName
+	}
+	if cfg.LogS3PathPrefix != nil {
+		c.S3PathPrefix = *cfg.LogS3PathPrefix
+	}
+
+	return c
+}
+
+// Validate returns an error if the intervals aren't positive, if the upload threshold is shorter than the
+// upload check interval, if the bucket name isn't a valid S3 bucket name, or if the path prefix isn't a
+// valid template
+func (c *LogConfig) Validate() error {
+	var err *multierror.Error
+
+	for _, interval := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{key: AnnotationKeyLogUploadCheckInterval, value: c.UploadCheckInterval},
+		{key: AnnotationKeyLogUploadThresholdTime, value: c.UploadThresholdTime},
+		{key: AnnotationKeyLogStdioCheckInterval, value: c.StdioCheckInterval},
+	} {
+		if interval.value <= 0 {
+			err = multierror.Append(err, fmt.Errorf("%s must be positive: %s", interval.key, interval.value))
+		}
+	}
+
+	if c.UploadThresholdTime < c.UploadCheckInterval {
+		err = multierror.Append(err, fmt.Errorf("%s (%s) must not be shorter than %s (%s)",
+			AnnotationKeyLogUploadThresholdTime, c.UploadThresholdTime, AnnotationKeyLogUploadCheckInterval, c.UploadCheckInterval))
+	}
+
+	if c.S3BucketName != "" {
+		if bErr := validateS3BucketName(c.S3BucketName); bErr != nil {
+			err = multierror.Append(err, fmt.Errorf("%s annotation is not a valid bucket name %s: %w", AnnotationKeyLogS3BucketName, c.S3BucketName, bErr))
+		}
+	}
+
+	return err.ErrorOrNil()
+}
+
+// validateS3BucketName checks the S3 bucket naming rules
+func validateS3BucketName(name string) error {
+	switch {
+	case !s3BucketNameRegexp.MatchString(name):
+		return errors.New("must be 3 to 63 lowercase letters, digits, dots and hyphens, starting and ending with a letter or digit")
+	case strings.Contains(name, ".."):
+		return errors.New("must not contain two adjacent dots")
+	case net.ParseIP(name) != nil:
+		return errors.New("must not be formatted as an IP address")
+	case strings.HasPrefix(name, "xn--"):
+		return errors.New("must not start with xn--")
+	case strings.HasSuffix(name, "-s3alias"):
+		return errors.New("must not end with -s3alias")
+	}
+	return nil
+}
+
+// S3KeyPrefix returns the prefix of the S3 keys of the logs of a task: S3PathPrefix, or the workload name if
+// it is empty, followed by the job ID and the task ID. Empty path segments are dropped, so the prefix of a pod
+// without a workload name or a path prefix is "<job ID>/<task ID>".
+func (c *LogConfig) S3KeyPrefix(cfg *Config) (string, error) {
+	if cfg.TaskID == nil || *cfg.TaskID == "" {
+		return "", errors.New("pod has no task ID")
+	}
+
+	prefix := c.S3PathPrefix
+	if prefix == "" && cfg.WorkloadName != nil {
+		prefix = *cfg.WorkloadName
+	}
+
+	segments := make([]string, 0, strings.Count(prefix, "/")+3)
+	for _, segment := range strings.Split(prefix, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if cfg.JobID != nil && *cfg.JobID != "" {
+		segments = append(segments, *cfg.JobID)
+	}
+	segments = append(segments, *cfg.TaskID)
+	return path.Join(segments...), nil
+}
@@ -0,0 +1,109 @@
+package pod
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestNewLogConfig(t *testing.T) {
+	c := NewLogConfig(&Config{})
+	assert.DeepEqual(t, *c, LogConfig{
+		UploadCheckInterval: 15 * time.Minute,
+		UploadThresholdTime: 6 * time.Hour,
+		StdioCheckInterval:  time.Minute,
+	})
+	assert.NilError(t, c.Validate())
+
+	uploadRegexp := regexp.MustCompile(`\.log$`)
+	c = NewLogConfig(&Config{
+		LogKeepLocalFile:       ptr.BoolPtr(true),
+		LogUploadCheckInterval: durationPtr("1m"),
+		LogUploadThresholdTime: durationPtr("3m"),
+		LogStdioCheckInterval:  durationPtr("2m"),
+		LogUploadRegExp:        uploadRegexp,
+		LogS3WriterIAMRole:     ptr.StringPtr("arn:aws:iam::0:role/LogWriterRole"),
+		LogS3BucketName:        ptr.StringPtr("titus-logs.us-east-1"),
+		LogS3PathPrefix:        ptr.StringPtr("logs/titus"),
+	})
+	assert.Equal(t, c.UploadRegexp, uploadRegexp)
+	c.UploadRegexp = nil
+	assert.DeepEqual(t, *c, LogConfig{
+		KeepLocalFile:       true,
+		UploadCheckInterval: time.Minute,
+		UploadThresholdTime: 3 * time.Minute,
+		StdioCheckInterval:  2 * time.Minute,
+		S3WriterIAMRole:     "arn:aws:iam::0:role/LogWriterRole",
+		S3BucketName:        "titus-logs.us-east-1",
+		S3PathPrefix:        "logs/titus",
+	})
+	assert.NilError(t, c.Validate())
+}
+
+func TestLogConfigValidate(t *testing.T) {
+	c := &LogConfig{
+		UploadCheckInterval: time.Hour,
+		UploadThresholdTime: time.Minute,
+		StdioCheckInterval:  0,
+		S3BucketName:        "Titus_Logs",
+	}
+	err := c.Validate()
+	assert.ErrorContains(t, err, "log.netflix.com/stdio-check-interval must be positive: 0s")
+	assert.ErrorContains(t, err, "log.netflix.com/upload-threshold-time (1m0s) must not be shorter than log.netflix.com/upload-check-interval (1h0m0s)")
+	assert.ErrorContains(t, err, "log.netflix.com/s3-bucket-name annotation is not a valid bucket name Titus_Logs: must be 3 to 63 lowercase letters")
+
+	for name, errMatch := range map[string]string{
+		"ab":               "must be 3 to 63",
+		"titus..logs":      "must not contain two adjacent dots",
+		"192.168.1.1":      "must not be formatted as an IP address",
+		"xn--titus":        "must not start with xn--",
+		"titus-s3alias":    "must not end with -s3alias",
+		"titus-logs-":      "must be 3 to 63",
+		"titus-logs-s3app": "",
+	} {
+		err := validateS3BucketName(name)
+		if errMatch == "" {
+			assert.NilError(t, err, name)
+		} else {
+			assert.ErrorContains(t, err, errMatch, name)
+		}
+	}
+}
+
+func TestS3KeyPrefix(t *testing.T) {
+	cfg := &Config{
+		JobID:         ptr.StringPtr("myjobid"),
+		TaskID:        ptr.StringPtr("mytaskid"),
+		WorkloadName:  ptr.StringPtr("myapp"),
+		WorkloadStack: ptr.StringPtr("mystack"),
+	}
+
+	prefix, err := NewLogConfig(cfg).S3KeyPrefix(cfg)
+	assert.NilError(t, err)
+	assert.Equal(t, prefix, "myapp/myjobid/mytaskid")
+
+	cfg.LogS3PathPrefix = ptr.StringPtr("/logs/titus/")
+	prefix, err = NewLogConfig(cfg).S3KeyPrefix(cfg)
+	assert.NilError(t, err)
+	assert.Equal(t, prefix, "logs/titus/myjobid/mytaskid")
+
+	// The prefix isn't interpreted
+	cfg.LogS3PathPrefix = ptr.StringPtr("logs/{{.App}}")
+	prefix, err = NewLogConfig(cfg).S3KeyPrefix(cfg)
+	assert.NilError(t, err)
+	assert.Equal(t, prefix, "logs/{{.App}}/myjobid/mytaskid")
+
+	noWorkload := &Config{JobID: cfg.JobID, TaskID: cfg.TaskID}
+	logConfig := NewLogConfig(noWorkload)
+	assert.NilError(t, logConfig.Validate())
+	prefix, err = logConfig.S3KeyPrefix(noWorkload)
+	assert.NilError(t, err)
+	assert.Equal(t, prefix, "myjobid/mytaskid")
+
+	cfg.TaskID = nil
+	_, err = NewLogConfig(cfg).S3KeyPrefix(cfg)
+	assert.ErrorContains(t, err, "pod has no task ID")
+}
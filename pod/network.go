@@ -0,0 +1,160 @@
+package pod
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/Netflix/titus-kube-common/node"
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	minVlanID = 1
+	maxVlanID = 4094
+)
+
+// NetworkAllocation is the network assignment of a pod, as recorded in its annotations
+type NetworkAllocation struct {
+	// Pod is the namespaced name of the pod
+	Pod           string
+	AllocationIdx *int
+	VlanID        *int
+	BranchENI     string
+	IPv4Address   net.IP
+	IPv6Address   net.IP
+}
+
+// GetNetworkAllocation returns the network assignment of a pod, or nil if the pod doesn't have one
+func GetNetworkAllocation(pod *corev1.Pod) (*NetworkAllocation, error) {
+	idxVal, hasIdx := pod.Annotations[AnnotationKeyAllocationIdx]
+	vlanVal, hasVlan := pod.Annotations[AnnotationKeyVlanID]
+	branchENI, hasBranchENI := pod.Annotations[AnnotationKeyBranchEniID]
+	ipv4Val, hasIPv4 := pod.Annotations[AnnotationKeyIPv4Address]
+	ipv6Val, hasIPv6 := pod.Annotations[AnnotationKeyIPv6Address]
+	if !hasIdx && !hasVlan && !hasBranchENI && !hasIPv4 && !hasIPv6 {
+		return nil, nil
+	}
+
+	alloc := &NetworkAllocation{
+		Pod:       pod.Namespace + "/" + pod.Name,
+		BranchENI: branchENI,
+	}
+	var err *multierror.Error
+	if hasIdx {
+		idx, pErr := strconv.Atoi(idxVal)
+		if pErr != nil {
+			err = multierror.Append(err, fmt.Errorf("%s annotation is not a valid int value %s: %w", AnnotationKeyAllocationIdx, idxVal, pErr))
+		} else {
+			alloc.AllocationIdx = &idx
+		}
+	}
+	if hasVlan {
+		vlan, pErr := strconv.Atoi(vlanVal)
+		if pErr != nil {
+			err = multierror.Append(err, fmt.Errorf("%s annotation is not a valid int value %s: %w", AnnotationKeyVlanID, vlanVal, pErr))
+		} else {
+			alloc.VlanID = &vlan
+		}
+	}
+	if hasIPv4 {
+		ip := net.ParseIP(ipv4Val)
+		if ip == nil || ip.To4() == nil {
+			err = multierror.Append(err, fmt.Errorf("%s annotation is not a valid IPv4 address %s", AnnotationKeyIPv4Address, ipv4Val))
+		} else {
+			alloc.IPv4Address = ip.To4()
+		}
+	}
+	if hasIPv6 {
+		ip := net.ParseIP(ipv6Val)
+		if ip == nil || ip.To4() != nil {
+			err = multierror.Append(err, fmt.Errorf("%s annotation is not a valid IPv6 address %s", AnnotationKeyIPv6Address, ipv6Val))
+		} else {
+			alloc.IPv6Address = ip
+		}
+	}
+
+	if err != nil {
+		return nil, err.ErrorOrNil()
+	}
+	return alloc, nil
+}
+
+// CheckNetworkAllocations checks the network assignments of the pods running on a node against the node's
+// capacity. It returns an error describing every problem found:
+//   - allocation indexes out of the range of the node's IPs, or used by several pods
+//   - VLAN IDs that aren't valid 802.1Q IDs
+//   - a branch ENI used with several VLANs, or a VLAN used by several branch ENIs
+//   - more branch ENIs than the node supports
+//
+// Each branch ENI is tagged with its own VLAN on the trunk ENI, and pods with the same security groups share a
+// branch ENI, so several pods using the same VLAN on the same branch ENI is expected and not reported. A VLAN
+// is only a duplicate when it is used by two different branch ENIs.
+//
+// The set can be nil when the node's capacity isn't known, in which case only the conflicts between the pods
+// are checked.
+func CheckNetworkAllocations(set *node.ENIResourceSet, pods []*corev1.Pod) error {
+	var err *multierror.Error
+	allocationIdxs := map[int]string{}
+	branches := map[string]bool{}
+	branchVlans := map[string]int{}
+	vlanBranches := map[int]string{}
+
+	for _, p := range pods {
+		alloc, pErr := GetNetworkAllocation(p)
+		if pErr != nil {
+			err = multierror.Append(err, fmt.Errorf("pod %s/%s: %w", p.Namespace, p.Name, pErr))
+			continue
+		}
+		if alloc == nil {
+			continue
+		}
+
+		if alloc.AllocationIdx != nil {
+			idx := *alloc.AllocationIdx
+			if set != nil && (idx < 0 || idx >= set.IPs) {
+				err = multierror.Append(err, fmt.Errorf("pod %s has allocation index %d, out of the node's range [0, %d)", alloc.Pod, idx, set.IPs))
+			}
+			if other, ok := allocationIdxs[idx]; ok {
+				err = multierror.Append(err, fmt.Errorf("pods %s and %s have the same allocation index %d", other, alloc.Pod, idx))
+			} else {
+				allocationIdxs[idx] = alloc.Pod
+			}
+		}
+
+		if alloc.BranchENI != "" {
+			branches[alloc.BranchENI] = true
+		}
+
+		if alloc.VlanID != nil {
+			vlan := *alloc.VlanID
+			if vlan < minVlanID || vlan > maxVlanID {
+				err = multierror.Append(err, fmt.Errorf("pod %s has an invalid VLAN ID %d", alloc.Pod, vlan))
+			}
+			if alloc.BranchENI == "" {
+				continue
+			}
+			if other, ok := branchVlans[alloc.BranchENI]; ok && other != vlan {
+				err = multierror.Append(err, fmt.Errorf("branch ENI %s is used with VLANs %d and %d", alloc.BranchENI, other, vlan))
+			}
+			if other, ok := vlanBranches[vlan]; ok && other != alloc.BranchENI {
+				err = multierror.Append(err, fmt.Errorf("VLAN %d is used by branch ENIs %s and %s", vlan, other, alloc.BranchENI))
+			}
+			branchVlans[alloc.BranchENI] = vlan
+			vlanBranches[vlan] = alloc.BranchENI
+		}
+	}
+
+	if set != nil && len(branches) > set.BranchENIs {
+		names := make([]string, 0, len(branches))
+		for branch := range branches {
+			names = append(names, branch)
+		}
+		sort.Strings(names)
+		err = multierror.Append(err, fmt.Errorf("pods use %d branch ENIs, more than the %d supported by the node: %v", len(names), set.BranchENIs, names))
+	}
+
+	return err.ErrorOrNil()
+}
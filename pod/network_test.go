@@ -0,0 +1,99 @@
+package pod
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Netflix/titus-kube-common/node"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func buildNetworkPod(name, allocationIdx, vlanID, branchENI string) *corev1.Pod {
+	annotations := map[string]string{}
+	for key, value := range map[string]string{
+		AnnotationKeyAllocationIdx: allocationIdx,
+		AnnotationKeyVlanID:        vlanID,
+		AnnotationKeyBranchEniID:   branchENI,
+	} {
+		if value != "" {
+			annotations[key] = value
+		}
+	}
+
+	pod := buildPod(annotations, map[string]string{})
+	pod.Name = name
+	return pod
+}
+
+func TestCheckNetworkAllocations(t *testing.T) {
+	set := &node.ENIResourceSet{ENIs: 4, BranchENIs: 2, IPs: 10}
+	pods := []*corev1.Pod{
+		buildNetworkPod("a", "0", "2", "eni-a"),
+		// Pods with the same security groups share a branch ENI
+		buildNetworkPod("b", "1", "2", "eni-a"),
+		buildNetworkPod("c", "2", "3", "eni-b"),
+		// Pods that aren't assigned yet are ignored
+		buildNetworkPod("d", "", "", ""),
+	}
+	assert.NilError(t, CheckNetworkAllocations(set, pods))
+
+	alloc, err := GetNetworkAllocation(pods[0])
+	assert.NilError(t, err)
+	assert.Equal(t, alloc.Pod, "default/a")
+	assert.Equal(t, *alloc.AllocationIdx, 0)
+	assert.Equal(t, *alloc.VlanID, 2)
+	assert.Equal(t, alloc.BranchENI, "eni-a")
+}
+
+func TestCheckNetworkAllocationsConflicts(t *testing.T) {
+	set := &node.ENIResourceSet{ENIs: 4, BranchENIs: 2, IPs: 10}
+	pods := []*corev1.Pod{
+		buildNetworkPod("a", "0", "2", "eni-a"),
+		buildNetworkPod("b", "0", "3", "eni-a"),
+		buildNetworkPod("c", "10", "2", "eni-b"),
+		buildNetworkPod("d", "3", "5000", "eni-c"),
+		buildNetworkPod("e", "four", "", ""),
+	}
+
+	err := CheckNetworkAllocations(set, pods)
+	assert.ErrorContains(t, err, "pods default/a and default/b have the same allocation index 0")
+	assert.ErrorContains(t, err, "branch ENI eni-a is used with VLANs 2 and 3")
+	assert.ErrorContains(t, err, "pod default/c has allocation index 10, out of the node's range [0, 10)")
+	assert.ErrorContains(t, err, "VLAN 2 is used by branch ENIs eni-a and eni-b")
+	assert.ErrorContains(t, err, "pod default/d has an invalid VLAN ID 5000")
+	assert.ErrorContains(t, err, "pods use 3 branch ENIs, more than the 2 supported by the node: [eni-a eni-b eni-c]")
+	assert.ErrorContains(t, err, "pod default/e:")
+	assert.ErrorContains(t, err, "network.netflix.com/allocation-idx annotation is not a valid int value four")
+}
+
+func TestCheckNetworkAllocationsUnknownCapacity(t *testing.T) {
+	pods := []*corev1.Pod{
+		buildNetworkPod("a", "0", "2", "eni-a"),
+		buildNetworkPod("b", "0", "3", "eni-b"),
+		// Without the node's capacity, any index and number of branch ENIs is accepted
+		buildNetworkPod("c", "1000", "4", "eni-c"),
+	}
+
+	err := CheckNetworkAllocations(nil, pods)
+	assert.ErrorContains(t, err, "pods default/a and default/b have the same allocation index 0")
+	assert.Assert(t, !strings.Contains(err.Error(), "out of the node's range"), err)
+	assert.Assert(t, !strings.Contains(err.Error(), "branch ENIs, more than"), err)
+}
+
+func TestGetNetworkAllocationAddresses(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyIPv4Address: "100.66.1.2",
+		AnnotationKeyIPv6Address: "2600:1f18::1",
+	}, map[string]string{})
+	alloc, err := GetNetworkAllocation(pod)
+	assert.NilError(t, err)
+	assert.Equal(t, alloc.IPv4Address.String(), "100.66.1.2")
+	assert.Equal(t, alloc.IPv6Address.String(), "2600:1f18::1")
+
+	pod.Annotations[AnnotationKeyIPv4Address] = "2600:1f18::2"
+	pod.Annotations[AnnotationKeyIPv6Address] = "100.66.1.2"
+	_, err = GetNetworkAllocation(pod)
+	assert.ErrorContains(t, err, "network.netflix.com/address-ipv4 annotation is not a valid IPv4 address 2600:1f18::2")
+	assert.ErrorContains(t, err, "network.netflix.com/address-ipv6 annotation is not a valid IPv6 address 100.66.1.2")
+}
@@ -0,0 +1,98 @@
+package pod
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SchedLatency is the scheduling latency a pod requests through AnnotationKeySchedLatencyReq
+type SchedLatency string
+
+const (
+	// SchedLatencyDelay pods tolerate waiting in the scheduling queue
+	SchedLatencyDelay SchedLatency = AnnotationValSchedLatencyDelay
+	// SchedLatencyFast pods should be scheduled as soon as possible
+	SchedLatencyFast SchedLatency = AnnotationValSchedLatencyFast
+)
+
+// IsValid returns true if the latency is one of the known values
+func (l SchedLatency) IsValid() bool {
+	return l == SchedLatencyDelay || l == SchedLatencyFast
+}
+
+// SchedSpreading is the spreading behavior a pod requests through AnnotationKeySchedSpreadingReq
+type SchedSpreading string
+
+const (
+	// SchedSpreadingPack pods are bin packed on as few nodes as possible
+	SchedSpreadingPack SchedSpreading = AnnotationValSchedSpreadingPack
+	// SchedSpreadingSpread pods are spread across nodes
+	SchedSpreadingSpread SchedSpreading = AnnotationValSchedSpreadingSpread
+)
+
+// IsValid returns true if the spreading is one of the known values
+func (s SchedSpreading) IsValid() bool {
+	return s == SchedSpreadingPack || s == SchedSpreadingSpread
+}
+
+// SchedulingPlacement is the trough placement the scheduler records on a pod once it is scheduled
+type SchedulingPlacement struct {
+	// InTrough is true if the pod was scheduled in a trough
+	InTrough bool
+	// TroughName is the name of the trough the pod was scheduled in, if any
+	TroughName string
+}
+
+// GetSchedulingPlacement returns the placement recorded on a pod, or nil if the scheduler hasn't recorded any.
+// As with IsScheduledInTrough, the pod is in a trough if it has the AnnotationKeyPodScheduledInTrough
+// annotation, whatever its value.
+func GetSchedulingPlacement(pod *corev1.Pod) *SchedulingPlacement {
+	troughName, hasTroughName := pod.Annotations[AnnotationKeyPodScheduledTroughName]
+	if !IsScheduledInTrough(pod) && !hasTroughName {
+		return nil
+	}
+
+	return &SchedulingPlacement{
+		InTrough:   IsScheduledInTrough(pod),
+		TroughName: troughName,
+	}
+}
+
+// Apply records the placement in the pod's annotations
+func (p *SchedulingPlacement) Apply(pod *corev1.Pod) {
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+
+	if p.InTrough {
+		pod.Annotations[AnnotationKeyPodScheduledInTrough] = strconv.FormatBool(true)
+	} else {
+		delete(pod.Annotations, AnnotationKeyPodScheduledInTrough)
+	}
+	if p.TroughName == "" {
+		delete(pod.Annotations, AnnotationKeyPodScheduledTroughName)
+	} else {
+		pod.Annotations[AnnotationKeyPodScheduledTroughName] = p.TroughName
+	}
+}
+
+// CheckConsistency returns an error if the placement contradicts itself, or the trough the pod requested.
+// requestedTrough may be nil if the pod didn't request a trough.
+func (p *SchedulingPlacement) CheckConsistency(requestedTrough *string) error {
+	if !p.InTrough {
+		if p.TroughName != "" {
+			return fmt.Errorf("pod is not scheduled in a trough but has a scheduled trough name: %s", p.TroughName)
+		}
+		return nil
+	}
+
+	if p.TroughName == "" {
+		return fmt.Errorf("pod is scheduled in a trough but has no scheduled trough name")
+	}
+	if requestedTrough != nil && *requestedTrough != "" && *requestedTrough != p.TroughName {
+		return fmt.Errorf("pod is scheduled in trough %s but requested trough %s", p.TroughName, *requestedTrough)
+	}
+	return nil
+}
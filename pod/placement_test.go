@@ -0,0 +1,116 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	ptr "k8s.io/utils/pointer"
+)
+
+func schedLatencyPtr(l SchedLatency) *SchedLatency {
+	return &l
+}
+
+func schedSpreadingPtr(s SchedSpreading) *SchedSpreading {
+	return &s
+}
+
+func TestParsePodSchedulingRequests(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeySchedLatencyReq:        AnnotationValSchedLatencyFast,
+		AnnotationKeySchedSpreadingReq:      AnnotationValSchedSpreadingPack,
+		AnnotationKeyRequestedTroughName:    "trough-a",
+		AnnotationKeyPodScheduledInTrough:   "true",
+		AnnotationKeyPodScheduledTroughName: "trough-a",
+	}, map[string]string{})
+
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, conf.SchedLatencyReq, schedLatencyPtr(SchedLatencyFast))
+	assert.DeepEqual(t, conf.SchedSpreadingReq, schedSpreadingPtr(SchedSpreadingPack))
+	assert.DeepEqual(t, conf.RequestedTroughName, ptr.StringPtr("trough-a"))
+	assert.DeepEqual(t, conf.ScheduledInTrough, ptr.BoolPtr(true))
+	assert.DeepEqual(t, conf.ScheduledTroughName, ptr.StringPtr("trough-a"))
+}
+
+func TestIsScheduledInTrough(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{})
+	assert.Assert(t, !IsScheduledInTrough(pod))
+
+	pod.Annotations[AnnotationKeyPodScheduledInTrough] = "true"
+	assert.Assert(t, IsScheduledInTrough(pod))
+
+	// Only the presence of the annotation matters, as for pods annotated before the value was a boolean
+	pod.Annotations[AnnotationKeyPodScheduledInTrough] = ""
+	assert.Assert(t, IsScheduledInTrough(pod))
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, conf.ScheduledInTrough, ptr.BoolPtr(true))
+}
+
+func TestSchedulingPlacement(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{})
+	assert.Assert(t, GetSchedulingPlacement(pod) == nil)
+
+	(&SchedulingPlacement{InTrough: true, TroughName: "trough-a"}).Apply(pod)
+	placement := GetSchedulingPlacement(pod)
+	assert.DeepEqual(t, placement, &SchedulingPlacement{InTrough: true, TroughName: "trough-a"})
+	assert.Assert(t, IsScheduledInTrough(pod))
+
+	(&SchedulingPlacement{}).Apply(pod)
+	_, ok := pod.Annotations[AnnotationKeyPodScheduledTroughName]
+	assert.Assert(t, !ok)
+	assert.Assert(t, !IsScheduledInTrough(pod))
+	assert.Assert(t, GetSchedulingPlacement(pod) == nil)
+}
+
+func TestSchedulingPlacementConsistency(t *testing.T) {
+	tests := []struct {
+		desc      string
+		placement SchedulingPlacement
+		requested *string
+		errMatch  string
+	}{
+		{
+			desc:      "not in a trough",
+			placement: SchedulingPlacement{},
+			requested: ptr.StringPtr("trough-a"),
+		},
+		{
+			desc:      "in the requested trough",
+			placement: SchedulingPlacement{InTrough: true, TroughName: "trough-a"},
+			requested: ptr.StringPtr("trough-a"),
+		},
+		{
+			desc:      "in a trough without request",
+			placement: SchedulingPlacement{InTrough: true, TroughName: "trough-a"},
+		},
+		{
+			desc:      "in another trough",
+			placement: SchedulingPlacement{InTrough: true, TroughName: "trough-b"},
+			requested: ptr.StringPtr("trough-a"),
+			errMatch:  "pod is scheduled in trough trough-b but requested trough trough-a",
+		},
+		{
+			desc:      "in an unnamed trough",
+			placement: SchedulingPlacement{InTrough: true},
+			errMatch:  "pod is scheduled in a trough but has no scheduled trough name",
+		},
+		{
+			desc:      "trough name outside of a trough",
+			placement: SchedulingPlacement{TroughName: "trough-a"},
+			errMatch:  "pod is not scheduled in a trough but has a scheduled trough name: trough-a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.placement.CheckConsistency(tt.requested)
+			if tt.errMatch == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errMatch)
+			}
+		})
+	}
+}
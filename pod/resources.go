@@ -0,0 +1,154 @@
+package pod
+
+import (
+	"fmt"
+
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ContainerResources holds the resources requested by a single container of a pod
+type ContainerResources struct {
+	Name string
+	// Init is true for init containers
+	Init bool
+	// PlatformSidecar is true for containers injected by the platform (see IsPlatformSidecarContainer)
+	PlatformSidecar bool
+	Requests        corev1.ResourceList
+}
+
+// PodResources is the resource footprint of a pod. All resource lists use canonical resource names
+// and byte units, regardless of how the pod expresses them.
+type PodResources struct {
+	// Total is the amount of resources Kubernetes accounts for the pod: the larger of the sum of all containers
+	// and the largest init container, plus the pod overhead
+	Total corev1.ResourceList
+	// User is the sum of the requests of all the user containers (main container and user sidecars)
+	User corev1.ResourceList
+	// PlatformSidecars is the sum of the requests of all platform sidecar containers
+	PlatformSidecars corev1.ResourceList
+	// Init is the largest request of any init container, per resource
+	Init corev1.ResourceList
+	// Overhead is the pod overhead, as set by the runtime class
+	Overhead corev1.ResourceList
+	// OpportunisticCPU is the number of CPUs the scheduler assigned from opportunistic capacity, if any.
+	// Kubernetes does not account for those, so they are not part of Total.
+	OpportunisticCPU *resource.Quantity
+	// Containers is the per-container breakdown, init containers first
+	Containers []ContainerResources
+}
+
+// Footprint returns the total resources used by the pod, including opportunistic CPUs
+func (r *PodResources) Footprint() corev1.ResourceList {
+	if r.OpportunisticCPU == nil {
+		return resourceCommon.Add(r.Total)
+	}
+	return resourceCommon.Add(r.Total, corev1.ResourceList{corev1.ResourceCPU: *r.OpportunisticCPU})
+}
+
+// EffectiveResources computes the resources requested by a pod, following the Kubernetes accounting rules.
+// A container resource that only has a limit is considered requested at that limit, as Kubernetes does.
+// The quantities of pods without the byte units label are converted from the legacy units (see ByteUnitsEnabled),
+// so this is only meant for Titus pods. Use NativeResources for other pods.
+func EffectiveResources(pod *corev1.Pod) (*PodResources, error) {
+	byteUnits, err := ByteUnitsEnabled(pod)
+	if err != nil {
+		return nil, err
+	}
+	return effectiveResources(pod, byteUnits)
+}
+
+// NativeResources is like EffectiveResources, but takes the quantities as Kubernetes does, without any
+// legacy unit conversion. Pods that aren't Titus pods (see IsTitusPod), such as daemonset pods, never use
+// the legacy units.
+func NativeResources(pod *corev1.Pod) (*PodResources, error) {
+	return effectiveResources(pod, true)
+}
+
+func effectiveResources(pod *corev1.Pod, byteUnits bool) (*PodResources, error) {
+	var err error
+	res := &PodResources{
+		User:             corev1.ResourceList{},
+		PlatformSidecars: corev1.ResourceList{},
+		Init:             corev1.ResourceList{},
+	}
+
+	for i := range pod.Spec.InitContainers {
+		c := &pod.Spec.InitContainers[i]
+		requests, err := containerRequests(c, byteUnits)
+		if err != nil {
+			return nil, err
+		}
+		res.Containers = append(res.Containers, ContainerResources{
+			Name:     c.Name,
+			Init:     true,
+			Requests: requests,
+		})
+		res.Init = resourceCommon.Max(res.Init, requests)
+	}
+
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		requests, err := containerRequests(c, byteUnits)
+		if err != nil {
+			return nil, err
+		}
+		platformSidecar := IsPlatformSidecarContainer(c.Name, pod)
+		res.Containers = append(res.Containers, ContainerResources{
+			Name:            c.Name,
+			PlatformSidecar: platformSidecar,
+			Requests:        requests,
+		})
+		if platformSidecar {
+			res.PlatformSidecars = resourceCommon.Add(res.PlatformSidecars, requests)
+		} else {
+			res.User = resourceCommon.Add(res.User, requests)
+		}
+	}
+
+	res.Overhead, err = resourceCommon.Normalize(pod.Spec.Overhead)
+	if err != nil {
+		return nil, fmt.Errorf("pod has invalid overhead: %w", err)
+	}
+
+	containers := resourceCommon.Add(res.User, res.PlatformSidecars)
+	res.Total = resourceCommon.Add(resourceCommon.Max(containers, res.Init), res.Overhead)
+
+	if val, ok := pod.GetAnnotations()[AnnotationKeyOpportunisticCPU]; ok {
+		opportunisticCPU, err := resource.ParseQuantity(val)
+		if err != nil {
+			return nil, fmt.Errorf("%s annotation is not a valid resource value %s: %w", AnnotationKeyOpportunisticCPU, val, err)
+		}
+		res.OpportunisticCPU = &opportunisticCPU
+	}
+
+	return res, nil
+}
+
+// containerRequests returns the normalized requests of a container, defaulting missing requests to their limits
+func containerRequests(c *corev1.Container, byteUnits bool) (corev1.ResourceList, error) {
+	requests, err := resourceCommon.Normalize(c.Resources.Requests)
+	if err != nil {
+		return nil, fmt.Errorf("container %s has invalid resource requests: %w", c.Name, err)
+	}
+	limits, err := resourceCommon.Normalize(c.Resources.Limits)
+	if err != nil {
+		return nil, fmt.Errorf("container %s has invalid resource limits: %w", c.Name, err)
+	}
+
+	if requests == nil {
+		requests = corev1.ResourceList{}
+	}
+	for name, quantity := range limits {
+		if _, ok := requests[name]; !ok {
+			requests[name] = quantity
+		}
+	}
+
+	if !byteUnits {
+		requests = LegacyResourcesToByteUnits(requests)
+	}
+
+	return requests, nil
+}
@@ -0,0 +1,129 @@
+package pod
+
+import (
+	"testing"
+
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func assertResources(t *testing.T, actual corev1.ResourceList, expected map[corev1.ResourceName]string) {
+	t.Helper()
+	assert.Equal(t, len(actual), len(expected), "got %v", actual)
+	for name, val := range expected {
+		quantity := actual[name]
+		assert.Equal(t, quantity.Cmp(resource.MustParse(val)), 0, "%s: got %s, expected %s", name, quantity.String(), val)
+	}
+}
+
+func TestEffectiveResources(t *testing.T) {
+	pod := buildPod(map[string]string{
+		ContainerAnnotation("logs", AnnotationKeySuffixContainersSidecar): "true",
+		AnnotationKeyOpportunisticCPU:                                     "2",
+	}, map[string]string{LabelKeyByteUnitsEnabled: "true"})
+	pod.Spec.Containers = append(pod.Spec.Containers,
+		corev1.Container{
+			Name: "logs",
+			Resources: corev1.ResourceRequirements{
+				// Requests default to limits
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("256Mi"),
+				},
+			},
+		},
+		corev1.Container{
+			Name: "user-sidecar",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("1"),
+				},
+			},
+		},
+	)
+	pod.Spec.InitContainers = []corev1.Container{
+		{
+			Name: "init-1",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("8"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+		},
+		{
+			Name: "init-2",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+			},
+		},
+	}
+	pod.Spec.Overhead = corev1.ResourceList{
+		corev1.ResourceMemory: resource.MustParse("64Mi"),
+	}
+
+	res, err := EffectiveResources(pod)
+	assert.NilError(t, err)
+
+	assertResources(t, res.User, map[corev1.ResourceName]string{
+		corev1.ResourceCPU:                 "2",
+		corev1.ResourceMemory:              "512Mi",
+		corev1.ResourceEphemeralStorage:    "10Gi",
+		resourceCommon.ResourceNameGpu:     "0",
+		resourceCommon.ResourceNameNetwork: "128M",
+	})
+	assertResources(t, res.PlatformSidecars, map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    "500m",
+		corev1.ResourceMemory: "256Mi",
+	})
+	assertResources(t, res.Init, map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    "8",
+		corev1.ResourceMemory: "4Gi",
+	})
+	assertResources(t, res.Total, map[corev1.ResourceName]string{
+		corev1.ResourceCPU:                 "8",
+		corev1.ResourceMemory:              "4160Mi",
+		corev1.ResourceEphemeralStorage:    "10Gi",
+		resourceCommon.ResourceNameGpu:     "0",
+		resourceCommon.ResourceNameNetwork: "128M",
+	})
+	assert.Equal(t, res.OpportunisticCPU.Cmp(resource.MustParse("2")), 0)
+	footprint := res.Footprint()[corev1.ResourceCPU]
+	assert.Equal(t, footprint.Cmp(resource.MustParse("10")), 0)
+
+	assert.Equal(t, len(res.Containers), 5)
+	assert.Equal(t, res.Containers[0].Name, "init-1")
+	assert.Assert(t, res.Containers[0].Init)
+	assert.Equal(t, res.Containers[3].Name, "logs")
+	assert.Assert(t, res.Containers[3].PlatformSidecar)
+	assert.Assert(t, !res.Containers[4].PlatformSidecar)
+}
+
+func TestEffectiveResourcesLegacyUnits(t *testing.T) {
+	res, err := EffectiveResources(buildLegacyPod(map[string]string{}))
+	assert.NilError(t, err)
+	assertResources(t, res.Total, map[corev1.ResourceName]string{
+		corev1.ResourceCPU:                 "1",
+		corev1.ResourceMemory:              "512Mi",
+		corev1.ResourceEphemeralStorage:    "10Gi",
+		resourceCommon.ResourceNameGpu:     "0",
+		resourceCommon.ResourceNameNetwork: "128M",
+	})
+	assert.Assert(t, res.OpportunisticCPU == nil)
+}
+
+func TestEffectiveResourcesInvalid(t *testing.T) {
+	pod := buildPod(map[string]string{AnnotationKeyOpportunisticCPU: "lots"}, map[string]string{})
+	_, err := EffectiveResources(pod)
+	assert.ErrorContains(t, err, "opportunistic.scheduler.titus.netflix.com/cpu annotation is not a valid resource value lots")
+
+	pod = buildPod(map[string]string{}, map[string]string{})
+	pod.Spec.Containers[0].Resources.Requests[resourceCommon.ResourceNameGpuLegacy] = resource.MustParse("1")
+	_, err = EffectiveResources(pod)
+	assert.ErrorContains(t, err, "container task-id-in-container has invalid resource requests")
+}
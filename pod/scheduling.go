@@ -0,0 +1,199 @@
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Netflix/titus-kube-common/configmap"
+	"github.com/Netflix/titus-kube-common/node"
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ConfigMapKeySchedulingRules is the config map key holding a JSON list of SchedulingRule
+	ConfigMapKeySchedulingRules = "schedulingRules"
+)
+
+// SchedulingParameters are the scheduling settings of a pod
+type SchedulingParameters struct {
+	SchedulerName     string
+	PriorityClassName string
+	Spreading         SchedSpreading
+}
+
+// SchedulingRule maps the intent of a pod to scheduling parameters. Each non-empty match field must equal
+// the pod's value for the rule to match. Each non-empty result field decides that parameter, unless an
+// earlier matching rule already decided it.
+type SchedulingRule struct {
+	// Match fields
+	ResourcePool        string         `json:"resourcePool,omitempty"`
+	JobType             string         `json:"jobType,omitempty"`
+	Latency             SchedLatency   `json:"latency,omitempty"`
+	Spreading           SchedSpreading `json:"spreading,omitempty"`
+	PriorityClassIntent string         `json:"priorityClassIntent,omitempty"`
+
+	// Result fields
+	SchedulerName     string         `json:"schedulerName,omitempty"`
+	PriorityClassName string         `json:"priorityClassName,omitempty"`
+	SpreadingMode     SchedSpreading `json:"spreadingMode,omitempty"`
+}
+
+// SchedulingRules is an ordered list of scheduling rules
+type SchedulingRules []SchedulingRule
+
+// DefaultSchedulingRules returns the rules used by SchedulingDecision
+func DefaultSchedulingRules() SchedulingRules {
+	return SchedulingRules{
+		// Scheduler
+		{ResourcePool: node.LabelValueResourcePoolReserved, Spreading: SchedSpreadingPack, SchedulerName: SchedNameRservedBinpacking},
+		{ResourcePool: node.LabelValueResourcePoolReserved, SchedulerName: SchedNameReserved},
+		{ResourcePool: node.LabelValueResourcePoolElastic, SchedulerName: SchedNameMixed},
+		// Priority class: an explicit intent wins over the latency request
+		{PriorityClassIntent: BestEffortEvictablePriority, PriorityClassName: BestEffortEvictablePriority},
+		{PriorityClassIntent: NormalPriority, PriorityClassName: NormalPriority},
+		{Latency: SchedLatencyFast, PriorityClassName: SchedPriorityFast},
+		{Latency: SchedLatencyDelay, PriorityClassName: SchedPriorityDelay},
+		// Spreading: an explicit request wins, otherwise batch jobs are packed and everything else is spread
+		{Spreading: SchedSpreadingPack, SpreadingMode: SchedSpreadingPack},
+		{Spreading: SchedSpreadingSpread, SpreadingMode: SchedSpreadingSpread},
+		{JobType: JobTypeBatch, SpreadingMode: SchedSpreadingPack},
+		// Fallback
+		{
+			SchedulerName:     SchedNameDefault,
+			PriorityClassName: SchedPriorityMedium,
+			SpreadingMode:     SchedSpreadingSpread,
+		},
+	}
+}
+
+// SchedulingDecision returns the scheduling parameters of a pod, using the default rules
+func SchedulingDecision(cfg *Config) SchedulingParameters {
+	return DefaultSchedulingRules().Decide(cfg)
+}
+
+// Decide evaluates the rules in order against the pod's config. Parameters that no rule decides are left empty.
+func (rules SchedulingRules) Decide(cfg *Config) SchedulingParameters {
+	params := SchedulingParameters{}
+	for _, rule := range rules {
+		if !rule.matches(cfg) {
+			continue
+		}
+		if params.SchedulerName == "" {
+			params.SchedulerName = rule.SchedulerName
+		}
+		if params.PriorityClassName == "" {
+			params.PriorityClassName = rule.PriorityClassName
+		}
+		if params.Spreading == "" {
+			params.Spreading = rule.SpreadingMode
+		}
+	}
+
+	return params
+}
+
+func (rule SchedulingRule) matches(cfg *Config) bool {
+	conditions := []struct {
+		want  string
+		value *string
+	}{
+		{want: rule.ResourcePool, value: cfg.ResourcePool},
+		{want: rule.JobType, value: cfg.JobType},
+		{want: string(rule.Latency), value: (*string)(cfg.SchedLatencyReq)},
+		{want: string(rule.Spreading), value: (*string)(cfg.SchedSpreadingReq)},
+		{want: rule.PriorityClassIntent, value: cfg.PriorityClassIntent},
+	}
+
+	for _, c := range conditions {
+		if c.want != "" && (c.value == nil || *c.value != c.want) {
+			return false
+		}
+	}
+	return true
+}
+
+func (rule SchedulingRule) validate() error {
+	var err *multierror.Error
+
+	if rule.SchedulerName == "" && rule.PriorityClassName == "" && rule.SpreadingMode == "" {
+		err = multierror.Append(err, fmt.Errorf("rule %+v does not decide any scheduling parameter", rule))
+	}
+	if rule.Latency != "" && !rule.Latency.IsValid() {
+		err = multierror.Append(err, fmt.Errorf("rule %+v has an invalid latency: %s", rule, rule.Latency))
+	}
+	for _, spreading := range []SchedSpreading{rule.Spreading, rule.SpreadingMode} {
+		if spreading != "" && !spreading.IsValid() {
+			err = multierror.Append(err, fmt.Errorf("rule %+v has an invalid spreading: %s", rule, spreading))
+		}
+	}
+
+	return err.ErrorOrNil()
+}
+
+// ParseSchedulingRules reads the scheduling rules from config map data. The default rules are returned
+// if the data doesn't contain any rules.
+func ParseSchedulingRules(data map[string]string) (SchedulingRules, error) {
+	raw, ok := data[ConfigMapKeySchedulingRules]
+	if !ok {
+		return DefaultSchedulingRules(), nil
+	}
+
+	var rules SchedulingRules
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		return nil, fmt.Errorf("%s is not a valid list of scheduling rules: %w", ConfigMapKeySchedulingRules, err)
+	}
+
+	var err *multierror.Error
+	for _, rule := range rules {
+		if vErr := rule.validate(); vErr != nil {
+			err = multierror.Append(err, vErr)
+		}
+	}
+
+	if err != nil {
+		return nil, err.ErrorOrNil()
+	}
+	return rules, nil
+}
+
+// SchedulingRulesConfigMapper is a configmap.ConfigMapper producing SchedulingRules, to keep the rules
+// up to date with a config map through configmap.NewDynamicConfig
+func SchedulingRulesConfigMapper(rawCurrent map[string]string, previous *configmap.ConfigState) (interface{}, error) {
+	return ParseSchedulingRules(rawCurrent)
+}
+
+// resourcePoolFromPod returns the resource pool a pod is constrained to, either through its node selector or
+// through a required node affinity on a single resource pool. Node selector terms are ORed, so the pod is only
+// constrained to a pool if every term pins that same pool.
+func resourcePoolFromPod(pod *corev1.Pod) *string {
+	if pool, ok := pod.Spec.NodeSelector[node.LabelKeyResourcePool]; ok {
+		return &pool
+	}
+
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return nil
+	}
+	var pool *string
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		termPool := resourcePoolFromTerm(term)
+		if termPool == nil || (pool != nil && *pool != *termPool) {
+			return nil
+		}
+		pool = termPool
+	}
+
+	return pool
+}
+
+// resourcePoolFromTerm returns the resource pool a node selector term pins with a single value In expression
+func resourcePoolFromTerm(term corev1.NodeSelectorTerm) *string {
+	for _, expr := range term.MatchExpressions {
+		if expr.Key == node.LabelKeyResourcePool && expr.Operator == corev1.NodeSelectorOpIn && len(expr.Values) == 1 {
+			pool := expr.Values[0]
+			return &pool
+		}
+	}
+	return nil
+}
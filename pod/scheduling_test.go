@@ -0,0 +1,163 @@
+package pod
+
+import (
+	"testing"
+
+	"github.com/Netflix/titus-kube-common/configmap"
+	"github.com/Netflix/titus-kube-common/node"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestSchedulingDecision(t *testing.T) {
+	tests := []struct {
+		desc string
+		cfg  Config
+		want SchedulingParameters
+	}{
+		{
+			desc: "no intent",
+			cfg:  Config{},
+			want: SchedulingParameters{
+				SchedulerName:     SchedNameDefault,
+				PriorityClassName: SchedPriorityMedium,
+				Spreading:         SchedSpreadingSpread,
+			},
+		},
+		{
+			desc: "reserved batch job",
+			cfg: Config{
+				JobType:      ptr.StringPtr("BATCH"),
+				ResourcePool: ptr.StringPtr("reserved"),
+			},
+			want: SchedulingParameters{
+				SchedulerName:     SchedNameReserved,
+				PriorityClassName: SchedPriorityMedium,
+				Spreading:         SchedSpreadingPack,
+			},
+		},
+		{
+			desc: "reserved with packing requested",
+			cfg: Config{
+				ResourcePool:      ptr.StringPtr("reserved"),
+				SchedLatencyReq:   schedLatencyPtr(SchedLatencyFast),
+				SchedSpreadingReq: schedSpreadingPtr(SchedSpreadingPack),
+			},
+			want: SchedulingParameters{
+				SchedulerName:     SchedNameRservedBinpacking,
+				PriorityClassName: SchedPriorityFast,
+				Spreading:         SchedSpreadingPack,
+			},
+		},
+		{
+			desc: "elastic batch job requesting spreading with a priority class intent",
+			cfg: Config{
+				JobType:             ptr.StringPtr("BATCH"),
+				PriorityClassIntent: ptr.StringPtr(BestEffortEvictablePriority),
+				ResourcePool:        ptr.StringPtr("elastic"),
+				SchedLatencyReq:     schedLatencyPtr(SchedLatencyDelay),
+				SchedSpreadingReq:   schedSpreadingPtr(SchedSpreadingSpread),
+			},
+			want: SchedulingParameters{
+				SchedulerName:     SchedNameMixed,
+				PriorityClassName: BestEffortEvictablePriority,
+				Spreading:         SchedSpreadingSpread,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.DeepEqual(t, SchedulingDecision(&tt.cfg), tt.want)
+		})
+	}
+}
+
+func TestParseSchedulingRules(t *testing.T) {
+	rules, err := ParseSchedulingRules(map[string]string{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, rules, DefaultSchedulingRules())
+
+	dc, err := configmap.NewDynamicConfigFromMap(map[string]string{
+		ConfigMapKeySchedulingRules: `[
+			{"resourcePool": "elastic", "schedulerName": "my-scheduler"},
+			{"schedulerName": "default-scheduler", "priorityClassName": "normal", "spreadingMode": "pack"}
+		]`,
+	}, SchedulingRulesConfigMapper, configmap.Options{})
+	assert.NilError(t, err)
+
+	rules = dc.Get().(SchedulingRules)
+	params := rules.Decide(&Config{ResourcePool: ptr.StringPtr("elastic")})
+	assert.DeepEqual(t, params, SchedulingParameters{
+		SchedulerName:     "my-scheduler",
+		PriorityClassName: NormalPriority,
+		Spreading:         SchedSpreadingPack,
+	})
+}
+
+func TestParseSchedulingRulesInvalid(t *testing.T) {
+	_, err := ParseSchedulingRules(map[string]string{ConfigMapKeySchedulingRules: "{"})
+	assert.ErrorContains(t, err, "schedulingRules is not a valid list of scheduling rules")
+
+	_, err = ParseSchedulingRules(map[string]string{
+		ConfigMapKeySchedulingRules: `[{"resourcePool": "elastic"}, {"latency": "slow", "spreadingMode": "scatter"}]`,
+	})
+	assert.ErrorContains(t, err, "does not decide any scheduling parameter")
+	assert.ErrorContains(t, err, "has an invalid latency: slow")
+	assert.ErrorContains(t, err, "has an invalid spreading: scatter")
+}
+
+func TestParsePodResourcePool(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{})
+	pod.Spec.NodeSelector = map[string]string{node.LabelKeyResourcePool: "reserved"}
+	conf, err := PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, conf.ResourcePool, ptr.StringPtr("reserved"))
+
+	pod = buildPod(map[string]string{}, map[string]string{})
+	pod.Spec.Affinity = &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{
+								Key:      node.LabelKeyResourcePool,
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{"elastic"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	conf, err = PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, conf.ResourcePool, ptr.StringPtr("elastic"))
+
+	// Terms are ORed: the pool is only decided when every term pins the same one
+	poolTerm := func(pool string) corev1.NodeSelectorTerm {
+		return corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: node.LabelKeyResourcePool, Operator: corev1.NodeSelectorOpIn, Values: []string{pool}},
+			},
+		}
+	}
+	terms := &pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	*terms = []corev1.NodeSelectorTerm{poolTerm("elastic"), poolTerm("elastic")}
+	conf, err = PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, conf.ResourcePool, ptr.StringPtr("elastic"))
+
+	*terms = []corev1.NodeSelectorTerm{poolTerm("elastic"), poolTerm("reserved")}
+	conf, err = PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Assert(t, conf.ResourcePool == nil)
+
+	*terms = []corev1.NodeSelectorTerm{poolTerm("elastic"), {}}
+	conf, err = PodToConfig(pod)
+	assert.NilError(t, err)
+	assert.Assert(t, conf.ResourcePool == nil)
+}
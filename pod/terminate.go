@@ -0,0 +1,128 @@
+package pod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Netflix/titus-kube-common/internal/termination"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// terminationReasonCodes lists the known AnnotationValuePodTerminationReasonCode values
+var terminationReasonCodes = []string{
+	AnnotationValuePodTerminationReasonCodeKilled,
+	AnnotationValuePodTerminationReasonCodeEvicted,
+	AnnotationValuePodTerminationReasonCodePreempted,
+	AnnotationValuePodTerminationReasonCodeLost,
+}
+
+// ErrAlreadyTerminating is returned by Terminate when the pod is already being deleted, or another caller
+// already recorded a termination reason on it
+var ErrAlreadyTerminating = termination.ErrAlreadyTerminating
+
+// Terminate records the termination reason code, reason and caller on the pod (see AnnotationKeyPodTerminationReason),
+// then terminates it. Pods terminated with the evicted reason code go through the Eviction API, so that
+// disruption budgets are honored; all other pods are deleted. A nil grace period uses the pod's default.
+// If the eviction or deletion fails, for example because a disruption budget refuses the eviction, the
+// annotations are removed again.
+// If the pod was modified concurrently, the latest version is checked, and Terminate stops with
+// ErrAlreadyTerminating if the pod is already being deleted or has a termination reason.
+//
+// Unlike node.Terminate, this takes a client-go clientset, as the controller-runtime client can't create evictions.
+func Terminate(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod, code, reason, caller string, gracePeriod *int64) error {
+	if !isTerminationReasonCode(code) {
+		return fmt.Errorf("%s is not a valid pod termination reason code", code)
+	}
+	if reason == "" {
+		return errors.New("pod termination reason must not be empty")
+	}
+	if caller == "" {
+		return errors.New("pod termination caller must not be empty")
+	}
+
+	annotations := map[string]string{
+		AnnotationKeyPodTerminationReason:     reason,
+		AnnotationKeyPodTerminationReasonCode: code,
+		AnnotationKeyPodTerminationByCaller:   caller,
+	}
+	terminationClient := &podTerminationClient{pods: clientset.CoreV1().Pods(pod.Namespace), name: pod.Name}
+	recorded, err := termination.Record(ctx, terminationClient, pod, AnnotationKeyPodTerminationReason, annotations)
+	if err != nil {
+		return fmt.Errorf("could not record termination reason on pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	if err := terminate(ctx, clientset, pod, code, gracePeriod); err != nil {
+		// The pod keeps running, so it must not look terminated
+		if revertErr := termination.Revert(ctx, terminationClient, recorded, annotations); revertErr != nil {
+			return fmt.Errorf("%w (and could not remove the termination reason: %s)", err, revertErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// terminate evicts or deletes the pod, depending on the termination reason code
+func terminate(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod, code string, gracePeriod *int64) error {
+	deleteOptions := metav1.DeleteOptions{GracePeriodSeconds: gracePeriod}
+	if pod.UID != "" {
+		deleteOptions.Preconditions = &metav1.Preconditions{UID: &pod.UID}
+	}
+
+	if code == AnnotationValuePodTerminationReasonCodeEvicted {
+		eviction := &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pod.Name,
+				Namespace: pod.Namespace,
+			},
+			DeleteOptions: &deleteOptions,
+		}
+		if err := clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction); err != nil {
+			return fmt.Errorf("could not evict pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+		return nil
+	}
+
+	if err := clientset.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, deleteOptions); err != nil {
+		return fmt.Errorf("could not delete pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	return nil
+}
+
+func isTerminationReasonCode(code string) bool {
+	for _, c := range terminationReasonCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// podTerminationClient gets and patches a single pod for termination.Record
+type podTerminationClient struct {
+	pods typedcorev1.PodInterface
+	name string
+}
+
+func (c *podTerminationClient) Get(ctx context.Context) (metav1.Object, error) {
+	pod, err := c.pods.Get(ctx, c.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+func (c *podTerminationClient) Patch(ctx context.Context, data []byte) (metav1.Object, error) {
+	pod, err := c.pods.Patch(ctx, c.name, types.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
@@ -0,0 +1,201 @@
+package pod
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestTerminate(t *testing.T) {
+	tests := []struct {
+		code      string
+		wantVerbs []string
+		deleted   bool
+	}{
+		{
+			code:      AnnotationValuePodTerminationReasonCodeKilled,
+			wantVerbs: []string{"patch", "delete"},
+			deleted:   true,
+		},
+		{
+			code:      AnnotationValuePodTerminationReasonCodeEvicted,
+			wantVerbs: []string{"patch", "create"},
+		},
+		{
+			code:      AnnotationValuePodTerminationReasonCodePreempted,
+			wantVerbs: []string{"patch", "delete"},
+			deleted:   true,
+		},
+		{
+			code:      AnnotationValuePodTerminationReasonCodeLost,
+			wantVerbs: []string{"patch", "delete"},
+			deleted:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			ctx := context.Background()
+			pod := buildPod(map[string]string{}, map[string]string{})
+			pod.UID = "pod-uid"
+			clientset := k8sfake.NewSimpleClientset(pod)
+
+			err := Terminate(ctx, clientset, pod, tt.code, "task killed by user", "titus-gateway", ptr.Int64Ptr(30))
+			assert.NilError(t, err)
+
+			var verbs []string
+			for _, action := range clientset.Actions() {
+				verbs = append(verbs, action.GetVerb())
+			}
+			assert.DeepEqual(t, verbs, tt.wantVerbs)
+
+			if tt.deleted {
+				deleteAction := clientset.Actions()[1].(k8stesting.DeleteAction)
+				assert.DeepEqual(t, deleteAction.GetDeleteOptions().GracePeriodSeconds, ptr.Int64Ptr(30))
+				assert.Equal(t, *deleteAction.GetDeleteOptions().Preconditions.UID, pod.UID)
+
+				_, err = clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+				assert.Assert(t, apierrors.IsNotFound(err))
+				return
+			}
+
+			createAction := clientset.Actions()[1].(k8stesting.CreateAction)
+			assert.Equal(t, createAction.GetSubresource(), "eviction")
+			eviction := createAction.GetObject().(*policyv1.Eviction)
+			assert.Equal(t, eviction.Name, pod.Name)
+			assert.DeepEqual(t, eviction.DeleteOptions.GracePeriodSeconds, ptr.Int64Ptr(30))
+
+			// The fake clientset does not act on evictions, so the annotated pod is still there
+			updated, err := clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+			assert.NilError(t, err)
+			assert.Equal(t, updated.Annotations[AnnotationKeyPodTerminationReasonCode], tt.code)
+			assert.Equal(t, updated.Annotations[AnnotationKeyPodTerminationReason], "task killed by user")
+			assert.Equal(t, updated.Annotations[AnnotationKeyPodTerminationByCaller], "titus-gateway")
+		})
+	}
+}
+
+func TestTerminateRetriesOnConflict(t *testing.T) {
+	ctx := context.Background()
+	pod := buildPod(map[string]string{}, map[string]string{})
+	pod.ResourceVersion = "5"
+	clientset := k8sfake.NewSimpleClientset(pod)
+
+	conflicts := 0
+	clientset.PrependReactor("patch", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, pod.Name, nil)
+	})
+
+	err := Terminate(ctx, clientset, pod, AnnotationValuePodTerminationReasonCodeLost, "node went away", "node-gc", nil)
+	assert.NilError(t, err)
+
+	var verbs []string
+	for _, action := range clientset.Actions() {
+		verbs = append(verbs, action.GetVerb())
+	}
+	assert.DeepEqual(t, verbs, []string{"patch", "get", "patch", "delete"})
+}
+
+func TestTerminateInvalidArguments(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{})
+	clientset := k8sfake.NewSimpleClientset(pod)
+
+	invalid := []struct {
+		code     string
+		reason   string
+		caller   string
+		errMatch string
+	}{
+		{
+			code:     "vanished",
+			reason:   "task killed by user",
+			caller:   "titus-gateway",
+			errMatch: "vanished is not a valid pod termination reason code",
+		},
+		{
+			code:     AnnotationValuePodTerminationReasonCodeKilled,
+			caller:   "titus-gateway",
+			errMatch: "pod termination reason must not be empty",
+		},
+		{
+			code:     AnnotationValuePodTerminationReasonCodeKilled,
+			reason:   "task killed by user",
+			errMatch: "pod termination caller must not be empty",
+		},
+	}
+
+	for _, tt := range invalid {
+		err := Terminate(context.Background(), clientset, pod, tt.code, tt.reason, tt.caller, nil)
+		assert.ErrorContains(t, err, tt.errMatch)
+	}
+	assert.Equal(t, len(clientset.Actions()), 0)
+
+	_, err := clientset.CoreV1().Pods(pod.Namespace).Get(context.Background(), pod.Name, metav1.GetOptions{})
+	assert.NilError(t, err)
+}
+
+func TestTerminateAlreadyDeleting(t *testing.T) {
+	ctx := context.Background()
+	pod := buildPod(map[string]string{}, map[string]string{})
+	pod.ResourceVersion = "5"
+	deleting := pod.DeepCopy()
+	deleting.DeletionTimestamp = &metav1.Time{Time: time.Unix(1602201163, 0)}
+	clientset := k8sfake.NewSimpleClientset(deleting)
+
+	clientset.PrependReactor("patch", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, pod.Name, nil)
+	})
+
+	err := Terminate(ctx, clientset, pod, AnnotationValuePodTerminationReasonCodeKilled, "task killed by user", "titus-gateway", nil)
+	assert.Assert(t, errors.Is(err, ErrAlreadyTerminating))
+	assert.ErrorContains(t, err, "could not record termination reason on pod default/foo: already being terminated: deletion was requested at")
+
+	var verbs []string
+	for _, action := range clientset.Actions() {
+		verbs = append(verbs, action.GetVerb())
+	}
+	assert.DeepEqual(t, verbs, []string{"patch", "get"})
+}
+
+func TestTerminateEvictionRefused(t *testing.T) {
+	ctx := context.Background()
+	pod := buildPod(map[string]string{AnnotationKeyJobID: "myjobid"}, map[string]string{})
+	clientset := k8sfake.NewSimpleClientset(pod)
+
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+	})
+
+	err := Terminate(ctx, clientset, pod, AnnotationValuePodTerminationReasonCodeEvicted, "node is draining", "node-drainer", nil)
+	assert.ErrorContains(t, err, "could not evict pod default/foo: Cannot evict pod as it would violate the pod's disruption budget.")
+	assert.Assert(t, apierrors.IsTooManyRequests(err))
+
+	var verbs []string
+	for _, action := range clientset.Actions() {
+		verbs = append(verbs, action.GetVerb())
+	}
+	assert.DeepEqual(t, verbs, []string{"patch", "create", "patch"})
+
+	// The pod is still running, without the termination annotations
+	updated, err := clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, updated.Annotations, map[string]string{AnnotationKeyJobID: "myjobid"})
+}
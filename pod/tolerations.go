@@ -0,0 +1,111 @@
+package pod
+
+import (
+	"strings"
+
+	"github.com/Netflix/titus-kube-common/node"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TolerationOptions describes the placement of a pod that isn't captured in its Config
+type TolerationOptions struct {
+	// Backend is the node backend the pod targets (one of the node.LabelValueBackend values).
+	// Defaults to node.LabelValueBackendKubelet.
+	Backend string
+	// Tier is the capacity tier the pod runs in, if it requires dedicated nodes. The pod's annotations don't
+	// record its tier.
+	Tier string
+}
+
+// TolerationsFor returns the tolerations of the Titus node taints a pod with the given config and placement needs.
+// Pods with GPUs or KVM enabled tolerate the GPU or KVM node taints. The pod tolerates the farzone taint of the
+// zone it is placed in (Config.AvailabilityZone), and the scheduler taint of the scheduler picked by
+// SchedulingDecision, unless that is the default scheduler.
+func TolerationsFor(cfg *Config, opts TolerationOptions) []corev1.Toleration {
+	backend := opts.Backend
+	if backend == "" {
+		backend = node.LabelValueBackendKubelet
+	}
+
+	tolerations := []corev1.Toleration{
+		{
+			Key:      node.TaintKeyBackend,
+			Operator: corev1.TolerationOpEqual,
+			Value:    backend,
+		},
+	}
+
+	if cfg.ResourceGPU != nil && cfg.ResourceGPU.Sign() > 0 {
+		tolerations = append(tolerations, corev1.Toleration{
+			Key:      node.TaintKeyGPUNode,
+			Operator: corev1.TolerationOpExists,
+		})
+	}
+
+	if cfg.KvmEnabled != nil && *cfg.KvmEnabled {
+		tolerations = append(tolerations, corev1.Toleration{
+			Key:      node.TaintKeyKvm,
+			Operator: corev1.TolerationOpExists,
+		})
+	}
+
+	zone := ""
+	if cfg.AvailabilityZone != nil {
+		zone = *cfg.AvailabilityZone
+	}
+	schedulerName := SchedulingDecision(cfg).SchedulerName
+	if schedulerName == SchedNameDefault {
+		schedulerName = ""
+	}
+
+	valueTolerations := []struct {
+		key   string
+		value string
+	}{
+		{key: node.TaintKeyFarzone, value: zone},
+		{key: node.TaintKeyScheduler, value: schedulerName},
+		{key: node.TaintKeyTier, value: opts.Tier},
+	}
+	for _, vt := range valueTolerations {
+		if vt.value != "" {
+			tolerations = append(tolerations, corev1.Toleration{
+				Key:      vt.key,
+				Operator: corev1.TolerationOpEqual,
+				Value:    vt.value,
+			})
+		}
+	}
+
+	return tolerations
+}
+
+// ToleratesNodeTaints returns true if the pod tolerates all the Titus taints of the node that prevent scheduling
+// or execution. Otherwise, it also returns the taints that aren't tolerated. Non-Titus taints are ignored.
+func ToleratesNodeTaints(pod *corev1.Pod, n *corev1.Node) (bool, []corev1.Taint) {
+	var untolerated []corev1.Taint
+	for i := range n.Spec.Taints {
+		taint := &n.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule || !isTitusTaint(taint) {
+			continue
+		}
+
+		tolerated := false
+		for j := range pod.Spec.Tolerations {
+			if pod.Spec.Tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			untolerated = append(untolerated, *taint)
+		}
+	}
+
+	return len(untolerated) == 0, untolerated
+}
+
+// isTitusTaint returns true if the taint's key is in the Titus domain
+func isTitusTaint(taint *corev1.Taint) bool {
+	domain := strings.SplitN(taint.Key, "/", 2)[0]
+	return domain == DomainTitus || strings.HasSuffix(domain, "."+DomainTitus)
+}
@@ -0,0 +1,71 @@
+package pod
+
+import (
+	"testing"
+
+	"github.com/Netflix/titus-kube-common/node"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	ptr "k8s.io/utils/pointer"
+)
+
+func TestTolerationsFor(t *testing.T) {
+	cfg := &Config{
+		AvailabilityZone: ptr.StringPtr("us-east-1e"),
+		KvmEnabled:       ptr.BoolPtr(true),
+		ResourceGPU:      stringToResourcePtr("1"),
+		ResourcePool:     ptr.StringPtr(node.LabelValueResourcePoolReserved),
+	}
+
+	tolerations := TolerationsFor(cfg, TolerationOptions{
+		Backend: node.LabelValueBackendVirtualKubelet,
+		Tier:    "critical",
+	})
+	assert.DeepEqual(t, tolerations, []corev1.Toleration{
+		{Key: node.TaintKeyBackend, Operator: corev1.TolerationOpEqual, Value: node.LabelValueBackendVirtualKubelet},
+		{Key: node.TaintKeyGPUNode, Operator: corev1.TolerationOpExists},
+		{Key: node.TaintKeyKvm, Operator: corev1.TolerationOpExists},
+		{Key: node.TaintKeyFarzone, Operator: corev1.TolerationOpEqual, Value: "us-east-1e"},
+		{Key: node.TaintKeyScheduler, Operator: corev1.TolerationOpEqual, Value: SchedNameReserved},
+		{Key: node.TaintKeyTier, Operator: corev1.TolerationOpEqual, Value: "critical"},
+	})
+}
+
+func TestTolerationsForDefaults(t *testing.T) {
+	cfg := &Config{
+		KvmEnabled:  ptr.BoolPtr(false),
+		ResourceGPU: stringToResourcePtr("0"),
+	}
+
+	tolerations := TolerationsFor(cfg, TolerationOptions{})
+	assert.DeepEqual(t, tolerations, []corev1.Toleration{
+		{Key: node.TaintKeyBackend, Operator: corev1.TolerationOpEqual, Value: node.LabelValueBackendKubelet},
+	})
+}
+
+func TestToleratesNodeTaints(t *testing.T) {
+	pod := buildPod(map[string]string{}, map[string]string{})
+	pod.Spec.Tolerations = TolerationsFor(&Config{}, TolerationOptions{Tier: "flex"})
+
+	n := &corev1.Node{
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{
+				{Key: node.TaintKeyBackend, Value: node.LabelValueBackendKubelet, Effect: corev1.TaintEffectNoSchedule},
+				{Key: node.TaintKeyTier, Value: "flex", Effect: corev1.TaintEffectNoSchedule},
+				// Non-Titus and soft taints are ignored
+				{Key: "example.com/dedicated", Effect: corev1.TaintEffectNoSchedule},
+				{Key: node.TaintKeyNodeProblem, Effect: corev1.TaintEffectPreferNoSchedule},
+			},
+		},
+	}
+	ok, untolerated := ToleratesNodeTaints(pod, n)
+	assert.Assert(t, ok)
+	assert.Equal(t, len(untolerated), 0)
+
+	gpuTaint := corev1.Taint{Key: node.TaintKeyGPUNode, Value: "true", Effect: corev1.TaintEffectNoSchedule}
+	evacuateTaint := corev1.Taint{Key: node.TaintKeyNodeEvacuate, Effect: corev1.TaintEffectNoExecute}
+	n.Spec.Taints = append(n.Spec.Taints, gpuTaint, evacuateTaint)
+	ok, untolerated = ToleratesNodeTaints(pod, n)
+	assert.Assert(t, !ok)
+	assert.DeepEqual(t, untolerated, []corev1.Taint{gpuTaint, evacuateTaint})
+}
@@ -0,0 +1,95 @@
+package pod
+
+import (
+	"fmt"
+	"strconv"
+
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// Legacy pods express memory and disk in MB, which Titus has always treated as MiB
+	legacyBytesPerMB = 1024 * 1024
+	// Legacy pods express network bandwidth in Mbps
+	legacyBitsPerMbps = 1000 * 1000
+)
+
+// ByteUnitsEnabled returns true if the pod's memory and disk resources are expressed in bytes and
+// network bandwidth in bits per second. Pods without the byte units label use the legacy MB-based units.
+func ByteUnitsEnabled(pod *corev1.Pod) (bool, error) {
+	val, ok := pod.GetLabels()[LabelKeyByteUnitsEnabled]
+	if !ok {
+		return false, nil
+	}
+
+	enabled, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("%s label is not a valid boolean value %s: %w", LabelKeyByteUnitsEnabled, val, err)
+	}
+
+	return enabled, nil
+}
+
+// LegacyResourcesToByteUnits converts a resource list expressed in legacy MB-based units to bytes
+// (memory and disk) and bits per second (network). Other resources are copied as is.
+func LegacyResourcesToByteUnits(resources corev1.ResourceList) corev1.ResourceList {
+	if resources == nil {
+		return nil
+	}
+
+	converted := make(corev1.ResourceList, len(resources))
+	for name, quantity := range resources {
+		converted[name] = legacyQuantityToByteUnits(name, quantity)
+	}
+
+	return converted
+}
+
+// ConvertToByteUnits converts all container resources of a pod using legacy MB-based units to byte units,
+// and marks the pod with the byte units label. Pods already using byte units are left untouched.
+func ConvertToByteUnits(pod *corev1.Pod) error {
+	enabled, err := ByteUnitsEnabled(pod)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		return nil
+	}
+
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for i := range containers {
+			c := &containers[i]
+			c.Resources.Limits = LegacyResourcesToByteUnits(c.Resources.Limits)
+			c.Resources.Requests = LegacyResourcesToByteUnits(c.Resources.Requests)
+		}
+	}
+
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	pod.Labels[LabelKeyByteUnitsEnabled] = "true"
+
+	return nil
+}
+
+func legacyQuantityToByteUnits(name corev1.ResourceName, quantity resource.Quantity) resource.Quantity {
+	switch name {
+	case resourceCommon.ResourceNameMemory, resourceCommon.ResourceNameDisk, resourceCommon.ResourceNameDiskLegacy:
+		return *resource.NewQuantity(quantity.Value()*legacyBytesPerMB, resource.BinarySI)
+	case resourceCommon.ResourceNameNetwork, resourceCommon.ResourceNameNetworkLegacy:
+		return *resource.NewQuantity(quantity.Value()*legacyBitsPerMbps, resource.DecimalSI)
+	default:
+		return quantity.DeepCopy()
+	}
+}
+
+func legacyQuantityPtrToByteUnits(name corev1.ResourceName, quantity *resource.Quantity) *resource.Quantity {
+	if quantity == nil {
+		return nil
+	}
+
+	converted := legacyQuantityToByteUnits(name, *quantity)
+	return &converted
+}
@@ -0,0 +1,70 @@
+package pod
+
+import (
+	"testing"
+
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func buildLegacyPod(labels map[string]string) *corev1.Pod {
+	pod := buildPod(map[string]string{}, labels)
+	legacyResources := corev1.ResourceList{
+		corev1.ResourceCPU:                 resource.MustParse("1"),
+		corev1.ResourceMemory:              resource.MustParse("512"),
+		corev1.ResourceEphemeralStorage:    resource.MustParse("10240"),
+		resourceCommon.ResourceNameGpu:     resource.MustParse("0"),
+		resourceCommon.ResourceNameNetwork: resource.MustParse("128"),
+	}
+	pod.Spec.Containers[0].Resources.Limits = legacyResources
+	pod.Spec.Containers[0].Resources.Requests = legacyResources.DeepCopy()
+	return pod
+}
+
+func TestByteUnitsEnabled(t *testing.T) {
+	enabled, err := ByteUnitsEnabled(buildPod(map[string]string{}, map[string]string{}))
+	assert.NilError(t, err)
+	assert.Assert(t, !enabled)
+
+	enabled, err = ByteUnitsEnabled(buildPod(map[string]string{}, map[string]string{LabelKeyByteUnitsEnabled: "true"}))
+	assert.NilError(t, err)
+	assert.Assert(t, enabled)
+
+	_, err = ByteUnitsEnabled(buildPod(map[string]string{}, map[string]string{LabelKeyByteUnitsEnabled: "maybe"}))
+	assert.ErrorContains(t, err, "pod.titus.netflix.com/byteUnits label is not a valid boolean value maybe")
+}
+
+func TestParsePodLegacyUnits(t *testing.T) {
+	for _, labels := range []map[string]string{{}, {LabelKeyByteUnitsEnabled: "false"}} {
+		conf, err := PodToConfig(buildLegacyPod(labels))
+		assert.NilError(t, err)
+		assert.Equal(t, conf.ResourceCPU.Cmp(resource.MustParse("1")), 0)
+		assert.Equal(t, conf.ResourceMemory.Cmp(resource.MustParse("512Mi")), 0)
+		assert.Equal(t, conf.ResourceDisk.Cmp(resource.MustParse("10Gi")), 0)
+		assert.Equal(t, conf.ResourceNetwork.Cmp(resource.MustParse("128M")), 0)
+		assert.Equal(t, conf.ResourceGPU.Cmp(resource.MustParse("0")), 0)
+	}
+}
+
+func TestConvertToByteUnits(t *testing.T) {
+	pod := buildLegacyPod(map[string]string{})
+	assert.NilError(t, ConvertToByteUnits(pod))
+	assert.Equal(t, pod.Labels[LabelKeyByteUnitsEnabled], "true")
+
+	for _, resources := range []corev1.ResourceList{
+		pod.Spec.Containers[0].Resources.Limits,
+		pod.Spec.Containers[0].Resources.Requests,
+	} {
+		mem := resources[corev1.ResourceMemory]
+		assert.Equal(t, mem.Cmp(resource.MustParse("512Mi")), 0)
+		network := resources[resourceCommon.ResourceNameNetwork]
+		assert.Equal(t, network.Cmp(resource.MustParse("128M")), 0)
+	}
+
+	// Converting a pod already in byte units is a no-op
+	assert.NilError(t, ConvertToByteUnits(pod))
+	mem := pod.Spec.Containers[0].Resources.Limits[corev1.ResourceMemory]
+	assert.Equal(t, mem.Cmp(resource.MustParse("512Mi")), 0)
+}
@@ -0,0 +1,221 @@
+package pod
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Frigga naming rules, see https://github.com/Netflix/frigga
+var (
+	workloadAppRegexp      = regexp.MustCompile(`^[a-zA-Z0-9._]+$`)
+	workloadStackRegexp    = regexp.MustCompile(`^[a-zA-Z0-9._]*$`)
+	workloadDetailRegexp   = regexp.MustCompile(`^[a-zA-Z0-9._~^-]*$`)
+	workloadSequenceRegexp = regexp.MustCompile(`^v[0-9]{3,}$`)
+	asgNameRegexp          = regexp.MustCompile(`^(.*)-(v[0-9]{3,})$`)
+)
+
+// WorkloadIdentity is the Frigga name of a workload: its app, stack, detail and sequence. The cluster
+// name is app-stack-detail, and the ASG name is the cluster name followed by the sequence, such as
+// "myapp-mystack-mydetail-v001".
+type WorkloadIdentity struct {
+	App    string
+	Stack  string
+	Detail string
+	// Sequence is the push sequence of the ASG, such as "v001", empty for a cluster
+	Sequence string
+}
+
+// Validate returns an error if a part of the name has characters Frigga doesn't allow, or if the sequence
+// isn't a "v" followed by at least three digits
+func (w WorkloadIdentity) Validate() error {
+	var err *multierror.Error
+
+	if !workloadAppRegexp.MatchString(w.App) {
+		err = multierror.Append(err, fmt.Errorf("workload app %q must be letters, digits, dots and underscores", w.App))
+	}
+	if !workloadStackRegexp.MatchString(w.Stack) {
+		err = multierror.Append(err, fmt.Errorf("workload stack %q must be letters, digits, dots and underscores", w.Stack))
+	}
+	if !workloadDetailRegexp.MatchString(w.Detail) {
+		err = multierror.Append(err, fmt.Errorf("workload detail %q must be letters, digits, hyphens and the characters ._~^", w.Detail))
+	}
+	if w.Sequence != "" && !workloadSequenceRegexp.MatchString(w.Sequence) {
+		err = multierror.Append(err, fmt.Errorf("workload sequence %q must be a v followed by at least three digits", w.Sequence))
+	}
+
+	return err.ErrorOrNil()
+}
+
+// Cluster returns the cluster name, such as "myapp-mystack-mydetail". The stack is left empty, as in
+// "myapp--mydetail", when there is a detail but no stack.
+func (w WorkloadIdentity) Cluster() string {
+	switch {
+	case w.Detail != "":
+		return w.App + "-" + w.Stack + "-" + w.Detail
+	case w.Stack != "":
+		return w.App + "-" + w.Stack
+	default:
+		return w.App
+	}
+}
+
+// ASG returns the ASG name, such as "myapp-mystack-mydetail-v001", or the cluster name if there is no sequence
+func (w WorkloadIdentity) ASG() string {
+	if w.Sequence == "" {
+		return w.Cluster()
+	}
+	return w.Cluster() + "-" + w.Sequence
+}
+
+// ParseCluster parses a cluster name, such as "myapp-mystack-mydetail"
+func ParseCluster(name string) (*WorkloadIdentity, error) {
+	parts := strings.SplitN(name, "-", 3)
+	w := &WorkloadIdentity{App: parts[0]}
+	if len(parts) > 1 {
+		w.Stack = parts[1]
+	}
+	if len(parts) > 2 {
+		w.Detail = parts[2]
+	}
+
+	if err := w.Validate(); err != nil {
+		return nil, fmt.Errorf("%s is not a valid cluster name: %w", name, err)
+	}
+	return w, nil
+}
+
+// ParseASG parses an ASG name, such as "myapp-mystack-mydetail-v001". Names without a sequence are parsed
+// as cluster names.
+func ParseASG(name string) (*WorkloadIdentity, error) {
+	cluster, sequence := name, ""
+	if match := asgNameRegexp.FindStringSubmatch(name); match != nil {
+		cluster, sequence = match[1], match[2]
+	}
+
+	w, err := ParseCluster(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid ASG name: %w", name, err)
+	}
+	w.Sequence = sequence
+	return w, nil
+}
+
+// workloadIdentityKeys are the places each part of the workload identity is stored on a pod, from the
+// most to the least authoritative
+var workloadIdentityKeys = []struct {
+	part   string
+	field  func(*WorkloadIdentity) *string
+	copies []workloadIdentityCopy
+}{
+	{
+		part:  "app",
+		field: func(w *WorkloadIdentity) *string { return &w.App },
+		copies: []workloadIdentityCopy{
+			{key: AnnotationKeyWorkloadName, annotation: true},
+			{key: LabelKeyWorkloadName},
+			{key: LabelKeyAppLegacy},
+		},
+	},
+	{
+		part:  "stack",
+		field: func(w *WorkloadIdentity) *string { return &w.Stack },
+		copies: []workloadIdentityCopy{
+			{key: AnnotationKeyWorkloadStack, annotation: true},
+			{key: LabelKeyWorkloadStack},
+			{key: LabelKeyStackLegacy},
+		},
+	},
+	{
+		part:  "detail",
+		field: func(w *WorkloadIdentity) *string { return &w.Detail },
+		copies: []workloadIdentityCopy{
+			{key: AnnotationKeyWorkloadDetail, annotation: true},
+			{key: LabelKeyWorkloadDetail},
+			{key: LabelKeyDetailLegacy},
+		},
+	},
+	{
+		part:  "sequence",
+		field: func(w *WorkloadIdentity) *string { return &w.Sequence },
+		copies: []workloadIdentityCopy{
+			{key: AnnotationKeyWorkloadSequence, annotation: true},
+			{key: LabelKeyWorkloadSequence},
+			{key: LabelKeySequenceLegacy},
+		},
+	},
+}
+
+type workloadIdentityCopy struct {
+	key        string
+	annotation bool
+}
+
+func (c workloadIdentityCopy) lookup(pod *corev1.Pod) (string, bool) {
+	if c.annotation {
+		val, ok := pod.Annotations[c.key]
+		return val, ok
+	}
+	val, ok := pod.Labels[c.key]
+	return val, ok
+}
+
+func (c workloadIdentityCopy) String() string {
+	if c.annotation {
+		return "annotation " + c.key
+	}
+	return "label " + c.key
+}
+
+// GetWorkloadIdentity returns the workload identity of a pod. Each part is read from the workload annotation,
+// or else the workload label, or else the legacy label. Use CheckWorkloadIdentity to detect copies that
+// disagree.
+func GetWorkloadIdentity(pod *corev1.Pod) (*WorkloadIdentity, error) {
+	w := &WorkloadIdentity{}
+	for _, k := range workloadIdentityKeys {
+		for _, c := range k.copies {
+			if val, ok := c.lookup(pod); ok {
+				*k.field(w) = val
+				break
+			}
+		}
+	}
+
+	if w.App == "" {
+		return nil, errors.New("pod has no workload app")
+	}
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// CheckWorkloadIdentity returns an error for each part of the workload identity whose annotation, label and
+// legacy label copies on the pod don't agree. Missing copies are ignored.
+func CheckWorkloadIdentity(pod *corev1.Pod) error {
+	var err *multierror.Error
+
+	for _, k := range workloadIdentityKeys {
+		var first *workloadIdentityCopy
+		var firstVal string
+		for i, c := range k.copies {
+			val, ok := c.lookup(pod)
+			if !ok {
+				continue
+			}
+			if first == nil {
+				first, firstVal = &k.copies[i], val
+				continue
+			}
+			if val != firstVal {
+				err = multierror.Append(err, fmt.Errorf("workload %s is %q in %s, but %q in %s", k.part, firstVal, first, val, c))
+			}
+		}
+	}
+
+	return err.ErrorOrNil()
+}
@@ -0,0 +1,83 @@
+package pod
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestWorkloadIdentityNames(t *testing.T) {
+	tests := []struct {
+		asg      string
+		cluster  string
+		identity WorkloadIdentity
+	}{
+		{asg: "myapp", cluster: "myapp", identity: WorkloadIdentity{App: "myapp"}},
+		{asg: "myapp-v001", cluster: "myapp", identity: WorkloadIdentity{App: "myapp", Sequence: "v001"}},
+		{asg: "myapp-mystack-v1234", cluster: "myapp-mystack", identity: WorkloadIdentity{App: "myapp", Stack: "mystack", Sequence: "v1234"}},
+		{asg: "myapp--my-detail-v000", cluster: "myapp--my-detail", identity: WorkloadIdentity{App: "myapp", Detail: "my-detail", Sequence: "v000"}},
+		{asg: "my_app-my.stack-detail~1", cluster: "my_app-my.stack-detail~1", identity: WorkloadIdentity{App: "my_app", Stack: "my.stack", Detail: "detail~1"}},
+		// Sequences need at least three digits, so shorter ones are part of the detail
+		{asg: "myapp-mystack-v01", cluster: "myapp-mystack-v01", identity: WorkloadIdentity{App: "myapp", Stack: "mystack", Detail: "v01"}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.identity.ASG(), tt.asg)
+		assert.Equal(t, tt.identity.Cluster(), tt.cluster)
+
+		w, err := ParseASG(tt.asg)
+		assert.NilError(t, err, tt.asg)
+		assert.DeepEqual(t, *w, tt.identity)
+
+		w, err = ParseCluster(tt.cluster)
+		assert.NilError(t, err, tt.cluster)
+		assert.Equal(t, w.Cluster(), tt.cluster)
+	}
+}
+
+func TestWorkloadIdentityInvalid(t *testing.T) {
+	_, err := ParseASG("-mystack-v001")
+	assert.ErrorContains(t, err, `-mystack-v001 is not a valid ASG name: -mystack is not a valid cluster name`)
+	assert.ErrorContains(t, err, `workload app "" must be letters, digits, dots and underscores`)
+
+	_, err = ParseCluster("my app-my$stack")
+	assert.ErrorContains(t, err, `workload app "my app" must be letters, digits, dots and underscores`)
+	assert.ErrorContains(t, err, `workload stack "my$stack" must be letters, digits, dots and underscores`)
+
+	err = WorkloadIdentity{App: "myapp", Detail: "a/b", Sequence: "001"}.Validate()
+	assert.ErrorContains(t, err, `workload detail "a/b" must be letters, digits, hyphens and the characters ._~^`)
+	assert.ErrorContains(t, err, `workload sequence "001" must be a v followed by at least three digits`)
+}
+
+func TestGetWorkloadIdentity(t *testing.T) {
+	pod := buildPod(map[string]string{
+		AnnotationKeyWorkloadName:     "myapp",
+		AnnotationKeyWorkloadSequence: "v002",
+	}, map[string]string{
+		LabelKeyWorkloadName:   "myapp",
+		LabelKeyStackLegacy:    "mystack",
+		LabelKeyDetailLegacy:   "mydetail",
+		LabelKeySequenceLegacy: "v002",
+	})
+
+	w, err := GetWorkloadIdentity(pod)
+	assert.NilError(t, err)
+	assert.Equal(t, w.ASG(), "myapp-mystack-mydetail-v002")
+	assert.NilError(t, CheckWorkloadIdentity(pod))
+
+	pod.Labels[LabelKeyWorkloadName] = "otherapp"
+	pod.Labels[LabelKeyAppLegacy] = "legacyapp"
+	pod.Labels[LabelKeySequenceLegacy] = "v001"
+	err = CheckWorkloadIdentity(pod)
+	assert.ErrorContains(t, err, `workload app is "myapp" in annotation workload.netflix.com/name, but "otherapp" in label workload.netflix.com/name`)
+	assert.ErrorContains(t, err, `workload app is "myapp" in annotation workload.netflix.com/name, but "legacyapp" in label netflix.com/applicationName`)
+	assert.ErrorContains(t, err, `workload sequence is "v002" in annotation workload.netflix.com/sequence, but "v001" in label netflix.com/sequence`)
+
+	// The annotations win
+	w, err = GetWorkloadIdentity(pod)
+	assert.NilError(t, err)
+	assert.Equal(t, w.ASG(), "myapp-mystack-mydetail-v002")
+
+	_, err = GetWorkloadIdentity(buildPod(map[string]string{}, map[string]string{}))
+	assert.ErrorContains(t, err, "pod has no workload app")
+}
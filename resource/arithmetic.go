@@ -0,0 +1,161 @@
+package resource
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+// The functions below operate on resource lists using Titus resource semantics:
+//   - resource names are normalized (see Normalize) before any computation
+//   - a resource missing from a list is equivalent to a zero quantity of that resource
+// Input lists are never modified.
+
+// Insufficiency describes a resource dimension for which a request does not fit
+type Insufficiency struct {
+	Name      corev1.ResourceName
+	Requested apiresource.Quantity
+	Available apiresource.Quantity
+	// Shortfall is the amount of the resource missing for the request to fit
+	Shortfall apiresource.Quantity
+}
+
+func (i Insufficiency) String() string {
+	return fmt.Sprintf("insufficient %s: requested %s, available %s, short by %s",
+		i.Name, i.Requested.String(), i.Available.String(), i.Shortfall.String())
+}
+
+// Add returns the sum of the given resource lists
+func Add(lists ...corev1.ResourceList) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for _, list := range lists {
+		for name, quantity := range normalizeLenient(list) {
+			sum := result[name]
+			sum.Add(quantity)
+			result[name] = sum
+		}
+	}
+	return result
+}
+
+// Subtract returns a - b. The result may contain negative quantities.
+func Subtract(a, b corev1.ResourceList) corev1.ResourceList {
+	result := normalizeLenient(a)
+	if result == nil {
+		result = corev1.ResourceList{}
+	}
+	for name, quantity := range normalizeLenient(b) {
+		diff := result[name]
+		diff.Sub(quantity)
+		result[name] = diff
+	}
+	return result
+}
+
+// Max returns, for every resource, the largest quantity found across the given resource lists
+func Max(lists ...corev1.ResourceList) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for _, list := range lists {
+		for name, quantity := range normalizeLenient(list) {
+			if current, ok := result[name]; !ok || quantity.Cmp(current) > 0 {
+				result[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	return result
+}
+
+// IsZero returns true if every resource in the list is zero
+func IsZero(list corev1.ResourceList) bool {
+	for _, quantity := range list {
+		if !quantity.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal returns true if both lists hold the same quantity of every resource
+func Equal(a, b corev1.ResourceList) bool {
+	return IsZero(Subtract(a, b))
+}
+
+// LessThanOrEqual returns true if a holds at most as much of every resource as b
+func LessThanOrEqual(a, b corev1.ResourceList) bool {
+	for _, quantity := range Subtract(a, b) {
+		if quantity.Sign() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Fits returns true if the request fits into the allocatable resources. Otherwise, it returns every dimension
+// that doesn't fit (sorted by resource name), along with how much of the resource is missing.
+func Fits(request, allocatable corev1.ResourceList) (bool, []Insufficiency) {
+	normalizedRequest := normalizeLenient(request)
+	normalizedAllocatable := normalizeLenient(allocatable)
+
+	var insufficient []Insufficiency
+	for name, requested := range normalizedRequest {
+		available := normalizedAllocatable[name]
+		if requested.Cmp(available) <= 0 {
+			continue
+		}
+
+		shortfall := requested.DeepCopy()
+		shortfall.Sub(available)
+		insufficient = append(insufficient, Insufficiency{
+			Name:      name,
+			Requested: requested,
+			Available: available,
+			Shortfall: shortfall,
+		})
+	}
+
+	sort.Slice(insufficient, func(i, j int) bool {
+		return insufficient[i].Name < insufficient[j].Name
+	})
+
+	return len(insufficient) == 0, insufficient
+}
+
+// DominantShare returns the resource for which the request represents the largest fraction of the capacity,
+// and that fraction. A non-zero request of a resource that is absent from the capacity has an infinite share.
+// An empty request has a share of zero.
+func DominantShare(request, capacity corev1.ResourceList) (corev1.ResourceName, float64) {
+	normalizedCapacity := normalizeLenient(capacity)
+
+	var dominant corev1.ResourceName
+	share := 0.0
+	for name, requested := range normalizeLenient(request) {
+		if requested.Sign() <= 0 {
+			continue
+		}
+
+		var s float64
+		available := normalizedCapacity[name]
+		if available.Sign() <= 0 {
+			s = math.Inf(1)
+		} else {
+			s = requested.AsApproximateFloat64() / available.AsApproximateFloat64()
+		}
+
+		// Break ties on the resource name, to be deterministic
+		if s > share || (s == share && name < dominant) {
+			dominant = name
+			share = s
+		}
+	}
+
+	return dominant, share
+}
+
+// normalizeLenient returns the normalized resource list, resolving conflicting aliases as described in Normalize
+func normalizeLenient(list corev1.ResourceList) corev1.ResourceList {
+	normalized, _ := Normalize(list)
+	return normalized
+}
@@ -0,0 +1,120 @@
+package resource
+
+import (
+	"math"
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+func resourceList(values map[corev1.ResourceName]string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	for name, val := range values {
+		list[name] = apiresource.MustParse(val)
+	}
+	return list
+}
+
+func assertQuantity(t *testing.T, list corev1.ResourceList, name corev1.ResourceName, expected string) {
+	t.Helper()
+	quantity := list[name]
+	assert.Equal(t, quantity.Cmp(apiresource.MustParse(expected)), 0, "%s: got %s, expected %s", name, quantity.String(), expected)
+}
+
+func TestAdd(t *testing.T) {
+	sum := Add(
+		resourceList(map[corev1.ResourceName]string{ResourceNameCpu: "1", ResourceNameMemory: "1Gi"}),
+		resourceList(map[corev1.ResourceName]string{ResourceNameCpu: "500m", ResourceNameNvidiaGpu: "1"}),
+		nil,
+	)
+	assertQuantity(t, sum, ResourceNameCpu, "1500m")
+	assertQuantity(t, sum, ResourceNameMemory, "1Gi")
+	assertQuantity(t, sum, ResourceNameGpu, "1")
+	assert.Equal(t, len(sum), 3)
+}
+
+func TestSubtract(t *testing.T) {
+	diff := Subtract(
+		resourceList(map[corev1.ResourceName]string{ResourceNameCpu: "4", ResourceNameMemory: "8Gi"}),
+		resourceList(map[corev1.ResourceName]string{ResourceNameCpu: "1", ResourceNameNetworkLegacy: "100M"}),
+	)
+	assertQuantity(t, diff, ResourceNameCpu, "3")
+	assertQuantity(t, diff, ResourceNameMemory, "8Gi")
+	assertQuantity(t, diff, ResourceNameNetwork, "-100M")
+}
+
+func TestMax(t *testing.T) {
+	result := Max(
+		resourceList(map[corev1.ResourceName]string{ResourceNameCpu: "4", ResourceNameMemory: "1Gi"}),
+		resourceList(map[corev1.ResourceName]string{ResourceNameCpu: "1", ResourceNameMemory: "8Gi"}),
+	)
+	assertQuantity(t, result, ResourceNameCpu, "4")
+	assertQuantity(t, result, ResourceNameMemory, "8Gi")
+}
+
+func TestCompare(t *testing.T) {
+	small := resourceList(map[corev1.ResourceName]string{ResourceNameCpu: "1"})
+	large := resourceList(map[corev1.ResourceName]string{ResourceNameCpu: "2", ResourceNameGpuLegacy: "1"})
+
+	assert.Assert(t, LessThanOrEqual(small, large))
+	assert.Assert(t, !LessThanOrEqual(large, small))
+	assert.Assert(t, LessThanOrEqual(nil, small))
+	assert.Assert(t, Equal(small, resourceList(map[corev1.ResourceName]string{ResourceNameCpu: "1000m", ResourceNameGpu: "0"})))
+	assert.Assert(t, !Equal(small, large))
+	assert.Assert(t, IsZero(Subtract(large, large)))
+}
+
+func TestFits(t *testing.T) {
+	allocatable := resourceList(map[corev1.ResourceName]string{
+		ResourceNameCpu:     "4",
+		ResourceNameMemory:  "16Gi",
+		ResourceNameNetwork: "1G",
+	})
+
+	fits, insufficient := Fits(resourceList(map[corev1.ResourceName]string{
+		ResourceNameCpu:           "4",
+		ResourceNameNetworkLegacy: "500M",
+	}), allocatable)
+	assert.Assert(t, fits)
+	assert.Equal(t, len(insufficient), 0)
+
+	fits, insufficient = Fits(resourceList(map[corev1.ResourceName]string{
+		ResourceNameCpu:       "6",
+		ResourceNameMemory:    "8Gi",
+		ResourceNameNvidiaGpu: "1",
+	}), allocatable)
+	assert.Assert(t, !fits)
+	assert.Equal(t, len(insufficient), 2)
+	assert.Equal(t, insufficient[0].Name, corev1.ResourceName(ResourceNameCpu))
+	assert.Equal(t, insufficient[0].Shortfall.Cmp(apiresource.MustParse("2")), 0)
+	assert.Equal(t, insufficient[0].String(), "insufficient cpu: requested 6, available 4, short by 2")
+	assert.Equal(t, insufficient[1].Name, corev1.ResourceName(ResourceNameGpu))
+	assert.Equal(t, insufficient[1].Shortfall.Cmp(apiresource.MustParse("1")), 0)
+}
+
+func TestDominantShare(t *testing.T) {
+	capacity := resourceList(map[corev1.ResourceName]string{
+		ResourceNameCpu:    "8",
+		ResourceNameMemory: "32Gi",
+	})
+
+	name, share := DominantShare(resourceList(map[corev1.ResourceName]string{
+		ResourceNameCpu:    "2",
+		ResourceNameMemory: "16Gi",
+	}), capacity)
+	assert.Equal(t, name, corev1.ResourceName(ResourceNameMemory))
+	assert.Equal(t, share, 0.5)
+
+	name, share = DominantShare(resourceList(map[corev1.ResourceName]string{
+		ResourceNameCpu: "1",
+		ResourceNameGpu: "1",
+	}), capacity)
+	assert.Equal(t, name, corev1.ResourceName(ResourceNameGpu))
+	assert.Assert(t, math.IsInf(share, 1))
+
+	name, share = DominantShare(nil, capacity)
+	assert.Equal(t, name, corev1.ResourceName(""))
+	assert.Equal(t, share, 0.0)
+}
@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// resourceAliases maps legacy and vendor-specific resource names to their canonical Titus names
+var resourceAliases = map[corev1.ResourceName]corev1.ResourceName{
+	ResourceNameGpuLegacy:     ResourceNameGpu,
+	ResourceNameNvidiaGpu:     ResourceNameGpu,
+	ResourceNameNetworkLegacy: ResourceNameNetwork,
+	ResourceNameDiskLegacy:    ResourceNameDisk,
+}
+
+// CanonicalName returns the canonical name of a resource. Legacy and vendor-specific names
+// (such as "gpu" or "nvidia.com/gpu") are mapped to their Titus equivalent, all other names are returned as is.
+func CanonicalName(name corev1.ResourceName) corev1.ResourceName {
+	if canonical, ok := resourceAliases[name]; ok {
+		return canonical
+	}
+	return name
+}
+
+// Normalize returns a copy of the resource list with every resource stored under its canonical name.
+// A resource present under several aliases is kept once if all the values agree, otherwise an error
+// describing every conflict is returned along with the normalized list (using the canonical name's value,
+// or the largest value if the canonical name isn't present).
+func Normalize(resources corev1.ResourceList) (corev1.ResourceList, error) {
+	if resources == nil {
+		return nil, nil
+	}
+
+	// Iterate in a stable order so that errors are deterministic
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	var err *multierror.Error
+	normalized := make(corev1.ResourceList, len(resources))
+	sources := map[corev1.ResourceName]corev1.ResourceName{}
+	for _, n := range names {
+		name := corev1.ResourceName(n)
+		quantity := resources[name]
+		canonical := CanonicalName(name)
+
+		existing, ok := normalized[canonical]
+		if !ok {
+			normalized[canonical] = quantity.DeepCopy()
+			sources[canonical] = name
+			continue
+		}
+
+		if existing.Cmp(quantity) != 0 {
+			err = multierror.Append(err, fmt.Errorf("resource %s has conflicting values: %s=%s, %s=%s",
+				canonical, sources[canonical], existing.String(), name, quantity.String()))
+			if name == canonical || (sources[canonical] != canonical && quantity.Cmp(existing) > 0) {
+				normalized[canonical] = quantity.DeepCopy()
+				sources[canonical] = name
+			}
+		}
+	}
+
+	return normalized, err.ErrorOrNil()
+}
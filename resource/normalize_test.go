@@ -0,0 +1,54 @@
+package resource
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestCanonicalName(t *testing.T) {
+	assert.Equal(t, CanonicalName(ResourceNameGpuLegacy), corev1.ResourceName(ResourceNameGpu))
+	assert.Equal(t, CanonicalName(ResourceNameNvidiaGpu), corev1.ResourceName(ResourceNameGpu))
+	assert.Equal(t, CanonicalName(ResourceNameNetworkLegacy), corev1.ResourceName(ResourceNameNetwork))
+	assert.Equal(t, CanonicalName(ResourceNameDiskLegacy), corev1.ResourceName(ResourceNameDisk))
+	assert.Equal(t, CanonicalName(ResourceNameCpu), corev1.ResourceName(ResourceNameCpu))
+	assert.Equal(t, CanonicalName("example.com/foo"), corev1.ResourceName("example.com/foo"))
+}
+
+func TestNormalize(t *testing.T) {
+	normalized, err := Normalize(corev1.ResourceList{
+		ResourceNameCpu:           apiresource.MustParse("2"),
+		ResourceNameNvidiaGpu:     apiresource.MustParse("1"),
+		ResourceNameGpuLegacy:     apiresource.MustParse("1"),
+		ResourceNameNetworkLegacy: apiresource.MustParse("128M"),
+		ResourceNameDiskLegacy:    apiresource.MustParse("10Gi"),
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, normalized, corev1.ResourceList{
+		ResourceNameCpu:     apiresource.MustParse("2"),
+		ResourceNameGpu:     apiresource.MustParse("1"),
+		ResourceNameNetwork: apiresource.MustParse("128M"),
+		ResourceNameDisk:    apiresource.MustParse("10Gi"),
+	})
+}
+
+func TestNormalizeConflict(t *testing.T) {
+	normalized, err := Normalize(corev1.ResourceList{
+		ResourceNameGpu:       apiresource.MustParse("1"),
+		ResourceNameNvidiaGpu: apiresource.MustParse("2"),
+		ResourceNameGpuLegacy: apiresource.MustParse("4"),
+	})
+	assert.ErrorContains(t, err, "resource titus/gpu has conflicting values: gpu=4, nvidia.com/gpu=2")
+	assert.ErrorContains(t, err, "resource titus/gpu has conflicting values: gpu=4, titus/gpu=1")
+	// The canonical name wins
+	gpu := normalized[ResourceNameGpu]
+	assert.Equal(t, gpu.Value(), int64(1))
+}
+
+func TestNormalizeNil(t *testing.T) {
+	normalized, err := Normalize(nil)
+	assert.NilError(t, err)
+	assert.Assert(t, normalized == nil)
+}
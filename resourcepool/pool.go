@@ -0,0 +1,125 @@
+package resourcepool
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Netflix/titus-kube-common/node"
+	"github.com/Netflix/titus-kube-common/pod"
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Pool is the capacity of a resource pool at a point in time. All resource lists use canonical resource
+// names and byte units.
+type Pool struct {
+	Name string
+	// Nodes are the member nodes of the pool, sorted by name
+	Nodes []*corev1.Node
+	// Allocatable is the sum of the allocatable resources of the member nodes
+	Allocatable corev1.ResourceList
+	// Requested is the sum of the resources accounted by Kubernetes for the non-terminal pods bound to member nodes
+	Requested corev1.ResourceList
+	// Free is Allocatable - Requested
+	Free corev1.ResourceList
+	// CapacityGroups breaks Requested down by capacity group. Pods without a capacity group are accounted
+	// under the empty name.
+	CapacityGroups map[string]corev1.ResourceList
+	// SkippedPods are the pods bound to member nodes whose resources could not be computed, keyed by
+	// namespaced name. They are not part of Requested.
+	SkippedPods map[string]error
+}
+
+// NodeResourcePool returns the name of the resource pool a node belongs to, or an empty string
+func NodeResourcePool(n *corev1.Node) string {
+	return n.Labels[node.LabelKeyResourcePool]
+}
+
+// IsMember returns true if the node belongs to the pool
+func (p *Pool) IsMember(n *corev1.Node) bool {
+	return NodeResourcePool(n) == p.Name
+}
+
+// NewPool computes the capacity of a resource pool from the given nodes and pods. Nodes outside of the pool,
+// and pods that are terminal or not bound to a member node, are ignored. Pods with invalid resources are
+// reported in SkippedPods.
+func NewPool(name string, nodes []*corev1.Node, pods []*corev1.Pod) *Pool {
+	pool := &Pool{
+		Name:           name,
+		Allocatable:    corev1.ResourceList{},
+		Requested:      corev1.ResourceList{},
+		CapacityGroups: map[string]corev1.ResourceList{},
+		SkippedPods:    map[string]error{},
+	}
+
+	members := map[string]bool{}
+	for _, n := range nodes {
+		if !pool.IsMember(n) {
+			continue
+		}
+		members[n.Name] = true
+		pool.Nodes = append(pool.Nodes, n)
+		pool.Allocatable = resourceCommon.Add(pool.Allocatable, n.Status.Allocatable)
+	}
+	sort.Slice(pool.Nodes, func(i, j int) bool {
+		return pool.Nodes[i].Name < pool.Nodes[j].Name
+	})
+
+	for _, p := range pods {
+		if !members[p.Spec.NodeName] || isTerminal(p) {
+			continue
+		}
+
+		resources, err := podResources(p)
+		if err != nil {
+			pool.SkippedPods[p.Namespace+"/"+p.Name] = fmt.Errorf("could not compute the resources of pod %s/%s: %w", p.Namespace, p.Name, err)
+			continue
+		}
+
+		pool.Requested = resourceCommon.Add(pool.Requested, resources.Total)
+		group := capacityGroup(p)
+		pool.CapacityGroups[group] = resourceCommon.Add(pool.CapacityGroups[group], resources.Total)
+	}
+
+	pool.Free = resourceCommon.Subtract(pool.Allocatable, pool.Requested)
+	return pool
+}
+
+// NewPools computes the capacity of every resource pool the given nodes belong to, keyed by pool name.
+// Nodes without a resource pool label are ignored.
+func NewPools(nodes []*corev1.Node, pods []*corev1.Pod) map[string]*Pool {
+	pools := map[string]*Pool{}
+	for _, n := range nodes {
+		name := NodeResourcePool(n)
+		if name == "" {
+			continue
+		}
+		if _, ok := pools[name]; ok {
+			continue
+		}
+
+		pools[name] = NewPool(name, nodes, pods)
+	}
+
+	return pools
+}
+
+// podResources returns the resources of a pod. Only Titus pods can use the legacy units.
+func podResources(p *corev1.Pod) (*pod.PodResources, error) {
+	if pod.IsTitusPod(p) {
+		return pod.EffectiveResources(p)
+	}
+	return pod.NativeResources(p)
+}
+
+func isTerminal(p *corev1.Pod) bool {
+	return p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed
+}
+
+// capacityGroup returns the capacity group of a pod, falling back to the legacy label
+func capacityGroup(p *corev1.Pod) string {
+	if group, ok := p.Labels[pod.LabelKeyCapacityGroup]; ok {
+		return group
+	}
+	return p.Labels[pod.LabelKeyCapacityGroupLegacy]
+}
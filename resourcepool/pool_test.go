@@ -0,0 +1,168 @@
+package resourcepool
+
+import (
+	"testing"
+
+	"github.com/Netflix/titus-kube-common/node"
+	"github.com/Netflix/titus-kube-common/pod"
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func buildNode(name, pool, cpu, memory string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{node.LabelKeyResourcePool: pool},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func buildPod(name, nodeName string, labels map[string]string, cpu, memory string) *corev1.Pod {
+	allLabels := map[string]string{
+		pod.LabelKeyByteUnitsEnabled: "true",
+		pod.LabelKeyTaskId:           name,
+	}
+	for k, v := range labels {
+		allLabels[k] = v
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    allLabels,
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(cpu),
+							corev1.ResourceMemory: resource.MustParse(memory),
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func resources(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func assertResources(t *testing.T, actual, expected corev1.ResourceList) {
+	t.Helper()
+	assert.Assert(t, resourceCommon.Equal(actual, expected), "got %v, expected %v", actual, expected)
+}
+
+func TestNewPool(t *testing.T) {
+	nodes := []*corev1.Node{
+		buildNode("node-b", ResourcePoolElastic, "16", "64Gi"),
+		buildNode("node-a", ResourcePoolElastic, "16", "64Gi"),
+		buildNode("node-c", ResourcePoolReserved, "32", "128Gi"),
+	}
+
+	finished := buildPod("finished", "node-a", nil, "8", "8Gi")
+	finished.Status.Phase = corev1.PodSucceeded
+	pods := []*corev1.Pod{
+		buildPod("batch-1", "node-a", map[string]string{pod.LabelKeyCapacityGroup: "batch"}, "4", "16Gi"),
+		buildPod("batch-2", "node-b", map[string]string{pod.LabelKeyCapacityGroupLegacy: "batch"}, "2", "4Gi"),
+		buildPod("web", "node-b", map[string]string{pod.LabelKeyCapacityGroup: "web"}, "1", "2Gi"),
+		buildPod("no-group", "node-b", nil, "1", "1Gi"),
+		buildPod("other-pool", "node-c", nil, "8", "8Gi"),
+		buildPod("pending", "", nil, "8", "8Gi"),
+		finished,
+	}
+
+	pool := NewPool(ResourcePoolElastic, nodes, pods)
+	assert.Equal(t, len(pool.SkippedPods), 0)
+	assert.Equal(t, len(pool.Nodes), 2)
+	assert.Equal(t, pool.Nodes[0].Name, "node-a")
+	assert.Equal(t, pool.Nodes[1].Name, "node-b")
+	assert.Assert(t, pool.IsMember(nodes[0]))
+	assert.Assert(t, !pool.IsMember(nodes[2]))
+
+	assertResources(t, pool.Allocatable, resources("32", "128Gi"))
+	assertResources(t, pool.Requested, resources("8", "23Gi"))
+	assertResources(t, pool.Free, resources("24", "105Gi"))
+
+	assert.Equal(t, len(pool.CapacityGroups), 3)
+	assertResources(t, pool.CapacityGroups["batch"], resources("6", "20Gi"))
+	assertResources(t, pool.CapacityGroups["web"], resources("1", "2Gi"))
+	assertResources(t, pool.CapacityGroups[""], resources("1", "1Gi"))
+}
+
+func TestNewPoolNormalizesResources(t *testing.T) {
+	n := buildNode("node-a", ResourcePoolElastic, "16", "64Gi")
+	n.Status.Allocatable[resourceCommon.ResourceNameNvidiaGpu] = resource.MustParse("4")
+	p := buildPod("gpu", "node-a", nil, "4", "16Gi")
+	p.Spec.Containers[0].Resources.Requests[resourceCommon.ResourceNameGpu] = resource.MustParse("1")
+
+	pool := NewPool(ResourcePoolElastic, []*corev1.Node{n}, []*corev1.Pod{p})
+	free := pool.Free[resourceCommon.ResourceNameGpu]
+	assert.Equal(t, free.Cmp(resource.MustParse("3")), 0, "got %s", free.String())
+}
+
+func TestNewPools(t *testing.T) {
+	nodes := []*corev1.Node{
+		buildNode("node-a", ResourcePoolElastic, "16", "64Gi"),
+		buildNode("node-b", ResourcePoolReserved, "32", "128Gi"),
+		buildNode("node-c", "", "8", "32Gi"),
+	}
+	pods := []*corev1.Pod{
+		buildPod("pod-a", "node-a", nil, "4", "16Gi"),
+		buildPod("pod-b", "node-b", nil, "8", "8Gi"),
+	}
+
+	pools := NewPools(nodes, pods)
+	assert.Equal(t, len(pools), 2)
+	assertResources(t, pools[ResourcePoolElastic].Free, resources("12", "48Gi"))
+	assertResources(t, pools[ResourcePoolReserved].Free, resources("24", "120Gi"))
+}
+
+func TestNewPoolUnitConversion(t *testing.T) {
+	n := buildNode("node-a", ResourcePoolElastic, "16", "64Gi")
+
+	// Legacy Titus pods express memory in MB
+	legacy := buildPod("legacy", "node-a", nil, "1", "1024")
+	delete(legacy.Labels, pod.LabelKeyByteUnitsEnabled)
+
+	// Daemonset pods aren't Titus pods, their quantities are never converted
+	daemonset := buildPod("daemonset", "node-a", nil, "1", "512Mi")
+	daemonset.Namespace = "kube-system"
+	daemonset.Labels = nil
+
+	pool := NewPool(ResourcePoolElastic, []*corev1.Node{n}, []*corev1.Pod{legacy, daemonset})
+	assertResources(t, pool.Requested, resources("2", "1536Mi"))
+}
+
+func TestNewPoolSkipsInvalidPods(t *testing.T) {
+	n := buildNode("node-a", ResourcePoolElastic, "16", "64Gi")
+	invalid := buildPod("invalid", "node-a", nil, "4", "16Gi")
+	invalid.Labels[pod.LabelKeyByteUnitsEnabled] = "maybe"
+
+	pool := NewPool(ResourcePoolElastic, []*corev1.Node{n}, []*corev1.Pod{
+		invalid,
+		buildPod("valid", "node-a", nil, "1", "1Gi"),
+	})
+	assertResources(t, pool.Requested, resources("1", "1Gi"))
+	assert.Equal(t, len(pool.SkippedPods), 1)
+	assert.ErrorContains(t, pool.SkippedPods["default/invalid"], "could not compute the resources of pod default/invalid: pod.titus.netflix.com/byteUnits label is not a valid boolean value maybe")
+}
@@ -0,0 +1,316 @@
+package resourcepool
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Netflix/titus-kube-common/configmap"
+	"github.com/Netflix/titus-kube-common/node"
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// PoolDefinition declares a resource pool and the nodes that belong to it
+type PoolDefinition struct {
+	Name string `json:"name"`
+	// SchedulerName is the scheduler placing pods in the pool, if the pool has a dedicated scheduler
+	SchedulerName string `json:"schedulerName,omitempty"`
+	// Taints are the taints every node of the pool must have
+	Taints []corev1.Taint `json:"taints,omitempty"`
+	// Labels are the labels every node of the pool must have
+	Labels map[string]string `json:"labels,omitempty"`
+	// InstanceTypes are the instance types allowed in the pool. Any instance type is allowed if empty.
+	InstanceTypes []string `json:"instanceTypes,omitempty"`
+	// Mock is true for pools made of mock nodes
+	Mock bool `json:"mock,omitempty"`
+}
+
+// Matches returns true if the node fulfills the requirements of the pool. The resource pool label of the node
+// is not taken into account.
+func (d *PoolDefinition) Matches(n *corev1.Node) bool {
+	if d.Mock != node.IsMockNode(n) {
+		return false
+	}
+	for key, value := range d.Labels {
+		if key == node.LabelKeyResourcePool {
+			continue
+		}
+		if actual, ok := n.Labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	for i := range d.Taints {
+		if !hasTaint(n, &d.Taints[i]) {
+			return false
+		}
+	}
+	return len(d.InstanceTypes) == 0 || containsString(d.InstanceTypes, n.Labels[node.LabelKeyInstanceType])
+}
+
+func (d *PoolDefinition) validate() error {
+	var err *multierror.Error
+
+	for _, msg := range validation.IsValidLabelValue(d.Name) {
+		err = multierror.Append(err, fmt.Errorf("resource pool name %q is not a valid label value: %s", d.Name, msg))
+	}
+	if d.Name == "" {
+		err = multierror.Append(err, fmt.Errorf("resource pool name is empty"))
+	}
+	if d.Mock != IsMockResourcePool(d.Name) {
+		err = multierror.Append(err, fmt.Errorf("resource pool %s has mock set to %t, which is inconsistent with its name", d.Name, d.Mock))
+	}
+	if pool, ok := d.Labels[node.LabelKeyResourcePool]; ok && pool != d.Name {
+		err = multierror.Append(err, fmt.Errorf("resource pool %s requires the %s label to be %s", d.Name, node.LabelKeyResourcePool, pool))
+	}
+
+	for _, taint := range d.Taints {
+		switch {
+		case taint.Key == "":
+			err = multierror.Append(err, fmt.Errorf("resource pool %s has a taint without key", d.Name))
+		case taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectPreferNoSchedule && taint.Effect != corev1.TaintEffectNoExecute:
+			err = multierror.Append(err, fmt.Errorf("resource pool %s has taint %s with an invalid effect: %q", d.Name, taint.Key, taint.Effect))
+		case taint.Key == node.TaintKeyScheduler && taint.Value != d.SchedulerName:
+			err = multierror.Append(err, fmt.Errorf("resource pool %s has scheduler %q but its scheduler taint is %q", d.Name, d.SchedulerName, taint.Value))
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, instanceType := range d.InstanceTypes {
+		if seen[instanceType] {
+			err = multierror.Append(err, fmt.Errorf("resource pool %s lists instance type %s more than once", d.Name, instanceType))
+		}
+		seen[instanceType] = true
+	}
+
+	return err.ErrorOrNil()
+}
+
+// overlaps returns true if a node could match both definitions with neither being more specific than the other:
+// they have an instance type in common, they don't require different values of the same label or taint, and
+// the requirements of neither strictly include the requirements of the other
+func (d *PoolDefinition) overlaps(other *PoolDefinition) bool {
+	if d.Mock != other.Mock {
+		return false
+	}
+	if !d.sharesInstanceType(other) || d.conflictsWith(other) {
+		return false
+	}
+	included, includes := d.requirementsIncludedIn(other), other.requirementsIncludedIn(d)
+	return included == includes
+}
+
+func (d *PoolDefinition) sharesInstanceType(other *PoolDefinition) bool {
+	if len(d.InstanceTypes) == 0 || len(other.InstanceTypes) == 0 {
+		return true
+	}
+	for _, instanceType := range d.InstanceTypes {
+		if containsString(other.InstanceTypes, instanceType) {
+			return true
+		}
+	}
+	return false
+}
+
+// conflictsWith returns true if no node can match both definitions, because they require different values of
+// the same label, or of the same taint. A node can't have two taints with the same key and effect.
+func (d *PoolDefinition) conflictsWith(other *PoolDefinition) bool {
+	for key, value := range d.Labels {
+		if key == node.LabelKeyResourcePool {
+			continue
+		}
+		if otherValue, ok := other.Labels[key]; ok && otherValue != value {
+			return true
+		}
+	}
+	for i := range d.Taints {
+		for j := range other.Taints {
+			if d.Taints[i].MatchTaint(&other.Taints[j]) && d.Taints[i].Value != other.Taints[j].Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// specificity is the number of requirements of the definition
+func (d *PoolDefinition) specificity() int {
+	count := len(d.Taints)
+	for key := range d.Labels {
+		if key != node.LabelKeyResourcePool {
+			count++
+		}
+	}
+	return count
+}
+
+func (d *PoolDefinition) requirementsIncludedIn(other *PoolDefinition) bool {
+	for key, value := range d.Labels {
+		if key == node.LabelKeyResourcePool {
+			continue
+		}
+		if otherValue, ok := other.Labels[key]; !ok || otherValue != value {
+			return false
+		}
+	}
+	for i := range d.Taints {
+		found := false
+		for j := range other.Taints {
+			if d.Taints[i].MatchTaint(&other.Taints[j]) && d.Taints[i].Value == other.Taints[j].Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Registry holds a validated set of resource pool definitions
+type Registry struct {
+	pools map[string]*PoolDefinition
+}
+
+// NewRegistry validates the definitions and returns a registry holding them
+func NewRegistry(definitions []PoolDefinition) (*Registry, error) {
+	var err *multierror.Error
+	registry := &Registry{pools: map[string]*PoolDefinition{}}
+
+	for i := range definitions {
+		d := definitions[i]
+		if vErr := d.validate(); vErr != nil {
+			err = multierror.Append(err, vErr)
+		}
+		if _, ok := registry.pools[d.Name]; ok {
+			err = multierror.Append(err, fmt.Errorf("resource pool %s is defined more than once", d.Name))
+			continue
+		}
+		registry.pools[d.Name] = &d
+	}
+
+	names := registry.Names()
+	for i := range names {
+		for j := i + 1; j < len(names); j++ {
+			if registry.pools[names[i]].overlaps(registry.pools[names[j]]) {
+				err = multierror.Append(err, fmt.Errorf("resource pools %s and %s overlap", names[i], names[j]))
+			}
+		}
+	}
+
+	if err != nil {
+		return nil, err.ErrorOrNil()
+	}
+	return registry, nil
+}
+
+// Get returns the definition of a pool
+func (r *Registry) Get(name string) (*PoolDefinition, bool) {
+	d, ok := r.pools[name]
+	return d, ok
+}
+
+// Names returns the sorted names of the pools in the registry
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.pools))
+	for name := range r.pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PoolForNode returns the definition of the pool the node matches, or nil if it matches none. When the node
+// matches several definitions, the most specific one is returned. An error is returned if the resource pool label
+// of the node disagrees with the matching definition.
+func (r *Registry) PoolForNode(n *corev1.Node) (*PoolDefinition, error) {
+	var match *PoolDefinition
+	for _, name := range r.Names() {
+		d := r.pools[name]
+		if d.Matches(n) && (match == nil || d.specificity() > match.specificity()) {
+			match = d
+		}
+	}
+
+	if match == nil {
+		return nil, nil
+	}
+	if pool, ok := n.Labels[node.LabelKeyResourcePool]; ok && pool != match.Name {
+		return match, fmt.Errorf("node %s matches resource pool %s but is labeled with resource pool %s", n.Name, match.Name, pool)
+	}
+	return match, nil
+}
+
+// ParseRegistry reads pool definitions from config map data. Each key is a pool name and its value is the
+// JSON PoolDefinition. The name in the definition may be omitted.
+func ParseRegistry(data map[string]string) (*Registry, error) {
+	var err *multierror.Error
+	definitions := make([]PoolDefinition, 0, len(data))
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		d := PoolDefinition{}
+		if pErr := json.Unmarshal([]byte(data[key]), &d); pErr != nil {
+			err = multierror.Append(err, fmt.Errorf("resource pool %s does not have a valid definition: %w", key, pErr))
+			continue
+		}
+		if d.Name == "" {
+			d.Name = key
+		} else if d.Name != key {
+			err = multierror.Append(err, fmt.Errorf("resource pool %s is defined under key %s", d.Name, key))
+			continue
+		}
+		definitions = append(definitions, d)
+	}
+
+	if err != nil {
+		return nil, err.ErrorOrNil()
+	}
+	return NewRegistry(definitions)
+}
+
+// RegistryConfigMapper is a configmap.ConfigMapper producing a *Registry
+func RegistryConfigMapper(rawCurrent map[string]string, previous *configmap.ConfigState) (interface{}, error) {
+	return ParseRegistry(rawCurrent)
+}
+
+// DynamicRegistry is a registry kept up to date with a config map. Invalid updates are rejected, and the
+// last valid registry is kept.
+type DynamicRegistry struct {
+	config configmap.DynamicConfig
+}
+
+// NewDynamicRegistry returns a registry backed by the given config, which must use RegistryConfigMapper
+func NewDynamicRegistry(config configmap.DynamicConfig) *DynamicRegistry {
+	return &DynamicRegistry{config: config}
+}
+
+// Get returns the current registry
+func (d *DynamicRegistry) Get() *Registry {
+	return d.config.Get().(*Registry)
+}
+
+func hasTaint(n *corev1.Node, taint *corev1.Taint) bool {
+	for i := range n.Spec.Taints {
+		if n.Spec.Taints[i].MatchTaint(taint) && n.Spec.Taints[i].Value == taint.Value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
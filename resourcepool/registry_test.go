@@ -0,0 +1,213 @@
+package resourcepool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Netflix/titus-kube-common/configmap"
+	"github.com/Netflix/titus-kube-common/node"
+	"gotest.tools/assert"
+	"gotest.tools/poll"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var registryData = map[string]string{
+	ResourcePoolElastic: `{
+		"instanceTypes": ["m5.metal", "r5.metal"]
+	}`,
+	ResourcePoolReserved: `{
+		"schedulerName": "titus-kube-scheduler-reserved",
+		"taints": [{"key": "node.titus.netflix.com/scheduler", "value": "titus-kube-scheduler-reserved", "effect": "NoSchedule"}],
+		"instanceTypes": ["r5.metal"]
+	}`,
+	ResourcePoolMockNodes: `{
+		"mock": true,
+		"labels": {"scaler.titus.netflix.com/resource-pool": "mock-nodes"}
+	}`,
+}
+
+func TestParseRegistry(t *testing.T) {
+	registry, err := ParseRegistry(registryData)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, registry.Names(), []string{ResourcePoolElastic, ResourcePoolMockNodes, ResourcePoolReserved})
+
+	reserved, ok := registry.Get(ResourcePoolReserved)
+	assert.Assert(t, ok)
+	assert.Equal(t, reserved.Name, ResourcePoolReserved)
+	assert.Equal(t, reserved.SchedulerName, "titus-kube-scheduler-reserved")
+
+	_, ok = registry.Get("unknown")
+	assert.Assert(t, !ok)
+}
+
+func TestRegistryPoolForNode(t *testing.T) {
+	registry, err := ParseRegistry(registryData)
+	assert.NilError(t, err)
+
+	n := buildNode("node-a", ResourcePoolElastic, "16", "64Gi")
+	n.Labels[node.LabelKeyInstanceType] = "m5.metal"
+	d, err := registry.PoolForNode(n)
+	assert.NilError(t, err)
+	assert.Equal(t, d.Name, ResourcePoolElastic)
+
+	n.Labels[node.LabelKeyInstanceType] = "r5.metal"
+	n.Spec.Taints = []corev1.Taint{
+		{Key: node.TaintKeyScheduler, Value: "titus-kube-scheduler-reserved", Effect: corev1.TaintEffectNoSchedule},
+	}
+	d, err = registry.PoolForNode(n)
+	assert.ErrorContains(t, err, "node node-a matches resource pool reserved but is labeled with resource pool elastic")
+	assert.Equal(t, d.Name, ResourcePoolReserved)
+
+	n.Labels[node.LabelKeyBackend] = node.LabelValueBackendMock
+	delete(n.Labels, node.LabelKeyResourcePool)
+	d, err = registry.PoolForNode(n)
+	assert.NilError(t, err)
+	assert.Equal(t, d.Name, ResourcePoolMockNodes)
+
+	n.Labels[node.LabelKeyBackend] = node.LabelValueBackendKubelet
+	n.Labels[node.LabelKeyInstanceType] = "p4d.24xlarge"
+	d, err = registry.PoolForNode(n)
+	assert.NilError(t, err)
+	assert.Assert(t, d == nil)
+}
+
+func TestParseRegistryInvalid(t *testing.T) {
+	tests := []struct {
+		desc     string
+		data     map[string]string
+		errMatch string
+	}{
+		{
+			desc:     "invalid JSON",
+			data:     map[string]string{ResourcePoolElastic: "{"},
+			errMatch: "resource pool elastic does not have a valid definition",
+		},
+		{
+			desc:     "name mismatch",
+			data:     map[string]string{ResourcePoolElastic: `{"name": "reserved"}`},
+			errMatch: "resource pool reserved is defined under key elastic",
+		},
+		{
+			desc:     "invalid name",
+			data:     map[string]string{"not/valid": `{}`},
+			errMatch: `resource pool name "not/valid" is not a valid label value`,
+		},
+		{
+			desc:     "mock flag",
+			data:     map[string]string{ResourcePoolElastic: `{"mock": true}`},
+			errMatch: "resource pool elastic has mock set to true, which is inconsistent with its name",
+		},
+		{
+			desc:     "resource pool label",
+			data:     map[string]string{ResourcePoolElastic: `{"labels": {"scaler.titus.netflix.com/resource-pool": "reserved"}}`},
+			errMatch: "resource pool elastic requires the scaler.titus.netflix.com/resource-pool label to be reserved",
+		},
+		{
+			desc: "scheduler taint",
+			data: map[string]string{
+				ResourcePoolReserved: `{"schedulerName": "a", "taints": [{"key": "node.titus.netflix.com/scheduler", "value": "b", "effect": "NoSchedule"}]}`,
+			},
+			errMatch: `resource pool reserved has scheduler "a" but its scheduler taint is "b"`,
+		},
+		{
+			desc:     "taint effect",
+			data:     map[string]string{ResourcePoolElastic: `{"taints": [{"key": "example.com/taint", "effect": "Never"}]}`},
+			errMatch: `resource pool elastic has taint example.com/taint with an invalid effect: "Never"`,
+		},
+		{
+			desc:     "duplicate instance type",
+			data:     map[string]string{ResourcePoolElastic: `{"instanceTypes": ["m5.metal", "m5.metal"]}`},
+			errMatch: "resource pool elastic lists instance type m5.metal more than once",
+		},
+		{
+			desc: "overlap",
+			data: map[string]string{
+				ResourcePoolElastic:  `{"labels": {"example.com/tier": "flex"}, "instanceTypes": ["m5.metal", "r5.metal"]}`,
+				ResourcePoolReserved: `{"labels": {"example.com/zone": "us-east-1a"}, "instanceTypes": ["r5.metal"]}`,
+			},
+			errMatch: "resource pools elastic and reserved overlap",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := ParseRegistry(tt.data)
+			assert.ErrorContains(t, err, tt.errMatch)
+		})
+	}
+}
+
+func TestParseRegistryDisjoint(t *testing.T) {
+	tests := []struct {
+		desc string
+		data map[string]string
+	}{
+		{
+			desc: "label values",
+			data: map[string]string{
+				ResourcePoolElastic:  `{"labels": {"example.com/tier": "flex"}, "instanceTypes": ["r5.metal"]}`,
+				ResourcePoolReserved: `{"labels": {"example.com/tier": "critical"}, "instanceTypes": ["r5.metal"]}`,
+			},
+		},
+		{
+			desc: "scheduler taint values",
+			data: map[string]string{
+				ResourcePoolElastic: `{
+					"schedulerName": "titus-kube-scheduler-binpacking",
+					"taints": [{"key": "node.titus.netflix.com/scheduler", "value": "titus-kube-scheduler-binpacking", "effect": "NoSchedule"}]
+				}`,
+				ResourcePoolReserved: `{
+					"schedulerName": "titus-kube-scheduler-reserved",
+					"taints": [{"key": "node.titus.netflix.com/scheduler", "value": "titus-kube-scheduler-reserved", "effect": "NoSchedule"}]
+				}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			registry, err := ParseRegistry(tt.data)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, registry.Names(), []string{ResourcePoolElastic, ResourcePoolReserved})
+		})
+	}
+}
+
+func TestDynamicRegistry(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "resource-pools"},
+		Data:       registryData,
+	}
+	clientset := fake.NewSimpleClientset(configMap)
+	rejected := make(chan error, 1)
+	config, err := configmap.NewDynamicConfigFromClientset(clientset, configMap.Name, RegistryConfigMapper, configmap.Options{
+		OnUpdateCallback: func(current, previous *configmap.ConfigState) {},
+		OnErrorCallback:  func(err error, previous *configmap.ConfigState) { rejected <- err },
+	})
+	assert.NilError(t, err)
+
+	registry := NewDynamicRegistry(config)
+	assert.DeepEqual(t, registry.Get().Names(), []string{ResourcePoolElastic, ResourcePoolMockNodes, ResourcePoolReserved})
+
+	updateConfigMap := func(data map[string]string) {
+		updated := configMap.DeepCopy()
+		updated.Data = data
+		_, err := clientset.CoreV1().ConfigMaps(configMap.Namespace).Update(context.TODO(), updated, metav1.UpdateOptions{})
+		assert.NilError(t, err)
+	}
+
+	updateConfigMap(map[string]string{ResourcePoolElastic: registryData[ResourcePoolElastic]})
+	poll.WaitOn(t, func(poll.LogT) poll.Result {
+		if names := registry.Get().Names(); len(names) != 1 {
+			return poll.Continue("registry has pools %v", names)
+		}
+		return poll.Success()
+	})
+
+	// Invalid updates are rejected, and the last valid registry is kept
+	updateConfigMap(map[string]string{ResourcePoolElastic: "{", ResourcePoolReserved: registryData[ResourcePoolReserved]})
+	assert.ErrorContains(t, <-rejected, "resource pool elastic does not have a valid definition")
+	assert.DeepEqual(t, registry.Get().Names(), []string{ResourcePoolElastic})
+}
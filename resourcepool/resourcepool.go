@@ -1,9 +1,11 @@
 package resourcepool
 
+import "github.com/Netflix/titus-kube-common/node"
+
 const (
-	ResourcePoolElastic   = "elastic"
-	ResourcePoolReserved  = "reserved"
-	ResourcePoolMockNodes = "mock-nodes"
+	ResourcePoolElastic   = node.LabelValueResourcePoolElastic
+	ResourcePoolReserved  = node.LabelValueResourcePoolReserved
+	ResourcePoolMockNodes = node.LabelValueResourcePoolMockNodes
 )
 
 func IsMockResourcePool(poolName string) bool {
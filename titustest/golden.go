@@ -0,0 +1,37 @@
+package titustest
+
+import (
+	"encoding/json"
+
+	"github.com/Netflix/titus-kube-common/pod"
+	"gotest.tools/assert"
+	"gotest.tools/golden"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AssertConfigGolden compares the JSON rendering of the fields set in the pod's config, as returned by
+// pod.PodToConfig, with the golden file testdata/<filename>. Unset fields are left out, so that adding a field
+// to pod.Config only changes the golden files of the pods that set it. Run the tests with -test.update-golden
+// to write the golden files.
+func AssertConfigGolden(t assert.TestingT, p *corev1.Pod, filename string) {
+	if ht, ok := t.(interface{ Helper() }); ok {
+		ht.Helper()
+	}
+
+	conf, err := pod.PodToConfig(p)
+	assert.NilError(t, err)
+	data, err := json.Marshal(conf)
+	assert.NilError(t, err)
+
+	fields := map[string]interface{}{}
+	assert.NilError(t, json.Unmarshal(data, &fields))
+	for name, value := range fields {
+		if value == nil {
+			delete(fields, name)
+		}
+	}
+
+	actual, err := json.MarshalIndent(fields, "", "  ")
+	assert.NilError(t, err)
+	golden.Assert(t, string(actual)+"\n", filename)
+}
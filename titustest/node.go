@@ -0,0 +1,99 @@
+package titustest
+
+import (
+	"fmt"
+
+	"github.com/Netflix/titus-kube-common/mock"
+	"github.com/Netflix/titus-kube-common/node"
+	"github.com/Netflix/titus-kube-common/resourcepool"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// InstanceID is the instance ID, and node name, of the nodes built by this package
+	InstanceID = "i-0123456789abcdef0"
+	// InstanceType is the instance type of the nodes built by this package
+	InstanceType = "m5.metal"
+	// ASG is the ASG of the nodes built by this package
+	ASG = "titusagent-v001"
+	// Zone is the availability zone of the nodes built by this package
+	Zone = "us-east-1a"
+)
+
+// NodeOption customizes a node built by this package
+type NodeOption func(*corev1.Node)
+
+// NewKubeletNode returns a ready node of the elastic resource pool, running pods with the kubelet
+func NewKubeletNode(opts ...NodeOption) *corev1.Node {
+	return newNode(node.LabelValueBackendKubelet, resourcepool.ResourcePoolElastic, opts)
+}
+
+// NewVirtualKubeletNode returns a ready node of the elastic resource pool, running pods with the Virtual Kubelet
+func NewVirtualKubeletNode(opts ...NodeOption) *corev1.Node {
+	return newNode(node.LabelValueBackendVirtualKubelet, resourcepool.ResourcePoolElastic, opts)
+}
+
+// NewMockNode returns a ready node of the mock-nodes resource pool
+func NewMockNode(opts ...NodeOption) *corev1.Node {
+	return newNode(node.LabelValueBackendMock, resourcepool.ResourcePoolMockNodes, opts)
+}
+
+// NewDecommissioningNode returns a kubelet node in the decommissioning lifecycle state
+func NewDecommissioningNode(opts ...NodeOption) *corev1.Node {
+	return NewKubeletNode(append([]NodeOption{WithLifecycleState(node.LifecycleStateDecommissioning)}, opts...)...)
+}
+
+func newNode(backend, pool string, opts []NodeOption) *corev1.Node {
+	n, err := mock.NewNode(mock.NodeOptions{
+		InstanceID:   InstanceID,
+		InstanceType: InstanceType,
+		ASG:          ASG,
+		Zone:         Zone,
+		Backend:      backend,
+		Labels:       map[string]string{node.LabelKeyResourcePool: pool},
+	})
+	if err != nil {
+		panic(fmt.Sprintf("could not build node: %s", err))
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// WithInstanceID sets the instance ID of the node, which is also its name and part of its provider ID
+func WithInstanceID(instanceID string) NodeOption {
+	return func(n *corev1.Node) {
+		n.Name = instanceID
+		n.Spec.ProviderID = fmt.Sprintf("aws:///%s/%s", n.Labels[corev1.LabelTopologyZone], instanceID)
+		n.Labels[node.LabelKeyInstanceID] = instanceID
+		n.Labels[corev1.LabelHostname] = instanceID
+		n.Annotations[node.AnnotationKeyInstanceID] = instanceID
+	}
+}
+
+// WithNodeLabels adds labels to the node
+func WithNodeLabels(labels map[string]string) NodeOption {
+	return func(n *corev1.Node) {
+		for k, v := range labels {
+			n.Labels[k] = v
+		}
+	}
+}
+
+// WithTaints adds taints to the node
+func WithTaints(taints ...corev1.Taint) NodeOption {
+	return func(n *corev1.Node) {
+		n.Spec.Taints = append(n.Spec.Taints, taints...)
+	}
+}
+
+// WithLifecycleState moves the node to a lifecycle state
+func WithLifecycleState(state node.LifecycleState) NodeOption {
+	return func(n *corev1.Node) {
+		if err := node.TransitionLifecycle(n, state); err != nil {
+			panic(fmt.Sprintf("could not move node to lifecycle state %s: %s", state, err))
+		}
+	}
+}
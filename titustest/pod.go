@@ -0,0 +1,269 @@
+// Package titustest provides realistic Titus pods and nodes for tests. Every constructor returns a new object,
+// which can be customized with option functions.
+package titustest
+
+import (
+	"strconv"
+
+	"github.com/Netflix/titus-kube-common/node"
+	"github.com/Netflix/titus-kube-common/pod"
+	resourceCommon "github.com/Netflix/titus-kube-common/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// TaskID is the task ID, and pod name, of the pods built by this package
+	TaskID = "3b1d04b6-5b7f-4a55-9c4e-1a2b3c4d5e6f"
+	// JobID is the job ID of the pods built by this package
+	JobID = "9e8d7c6b-5a49-4382-a1b0-c9d8e7f6a5b4"
+	// Image is the image of the main container of the pods built by this package
+	Image = "registry.example.com/titusops/helloworld@sha256:0b8a4c2e5d6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b"
+	// ImageTag is the tag the image of the main container was resolved from
+	ImageTag = "latest"
+	// MainContainerName is the name of the main container of v1 pods
+	MainContainerName = "main"
+
+	JobTypeBatch   = pod.JobTypeBatch
+	JobTypeService = pod.JobTypeService
+)
+
+// PodOption customizes a pod built by this package
+type PodOption func(*corev1.Pod)
+
+// NewPod returns a v1 schema pod of a batch job, with a single main container. The resources use byte units.
+func NewPod(opts ...PodOption) *corev1.Pod {
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TaskID,
+			Namespace: "default",
+			Annotations: map[string]string{
+				pod.AnnotationKeyPodSchemaVersion:       "1",
+				pod.AnnotationKeyJobID:                  JobID,
+				pod.AnnotationKeyJobType:                JobTypeBatch,
+				pod.AnnotationKeyJobAcceptedTimestampMs: "1602201163007",
+				pod.AnnotationKeyWorkloadName:           "helloworld",
+				pod.AnnotationKeyWorkloadStack:          "test",
+				pod.AnnotationKeyWorkloadSequence:       "v001",
+				pod.AnnotationKeyWorkloadOwnerEmail:     "owner@example.com",
+				pod.AnnotationKeyIAMRole:                "arn:aws:iam::123456789012:role/DefaultContainerRole",
+				pod.AnnotationKeyNetworkAccountID:       "123456789012",
+				pod.AnnotationKeyNetworkSecurityGroups:  "sg-0123456789abcdef0",
+				pod.AnnotationKeyNetworkSubnetIDs:       "subnet-0123456789abcdef0,subnet-0123456789abcdef1",
+				pod.AnnotationKeyEgressBandwidth:        "128M",
+				pod.AnnotationKeyIngressBandwidth:       "128M",
+				pod.ContainerAnnotation(MainContainerName, pod.AnnotationKeySuffixContainerImageTag): ImageTag,
+			},
+			Labels: map[string]string{
+				pod.LabelKeyByteUnitsEnabled: "true",
+				pod.LabelKeyCapacityGroup:    "DEFAULT",
+				pod.LabelKeyJobId:            JobID,
+				pod.LabelKeyTaskId:           TaskID,
+				pod.LabelKeyWorkloadName:     "helloworld",
+				pod.LabelKeyWorkloadStack:    "test",
+				pod.LabelKeyWorkloadSequence: "v001",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  MainContainerName,
+					Image: Image,
+					Resources: corev1.ResourceRequirements{
+						Limits:   Resources("2", "4Gi", "10Gi", "128M"),
+						Requests: Resources("2", "4Gi", "10Gi", "128M"),
+					},
+				},
+			},
+			Tolerations: pod.StandardTolerations(),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// NewBatchPod returns the pod of a batch job
+func NewBatchPod(opts ...PodOption) *corev1.Pod {
+	return NewPod(opts...)
+}
+
+// NewServicePod returns the pod of a service job
+func NewServicePod(opts ...PodOption) *corev1.Pod {
+	return NewPod(append([]PodOption{WithJobType(JobTypeService)}, opts...)...)
+}
+
+// NewGPUPod returns the pod of a batch job using GPUs
+func NewGPUPod(gpus int64, opts ...PodOption) *corev1.Pod {
+	return NewPod(append([]PodOption{WithGPUs(gpus)}, opts...)...)
+}
+
+// NewIPv6Pod returns the pod of a service job with an IPv6 address
+func NewIPv6Pod(opts ...PodOption) *corev1.Pod {
+	return NewServicePod(append([]PodOption{WithIPv6()}, opts...)...)
+}
+
+// NewPodWithSidecars returns the pod of a service job, with a platform sidecar and a user sidecar
+func NewPodWithSidecars(opts ...PodOption) *corev1.Pod {
+	return NewServicePod(append([]PodOption{
+		WithPlatformSidecar("logviewer", "stable"),
+		WithUserSidecar("envoy", "registry.example.com/envoy:1.0"),
+	}, opts...)...)
+}
+
+// NewLegacyPod returns a pod created before the v1 schema: the main container is named after the task ID,
+// the resources use the legacy units (see pod.ByteUnitsEnabled) and the capacity group and workload use the legacy
+// labels
+func NewLegacyPod(opts ...PodOption) *corev1.Pod {
+	p := NewPod()
+	delete(p.Annotations, pod.AnnotationKeyPodSchemaVersion)
+	delete(p.Annotations, pod.ContainerAnnotation(MainContainerName, pod.AnnotationKeySuffixContainerImageTag))
+	p.Annotations[pod.ContainerAnnotation(TaskID, pod.AnnotationKeySuffixContainerImageTag)] = ImageTag
+	delete(p.Labels, pod.LabelKeyByteUnitsEnabled)
+	delete(p.Labels, pod.LabelKeyCapacityGroup)
+	p.Labels[pod.LabelKeyCapacityGroupLegacy] = "DEFAULT"
+	delete(p.Labels, pod.LabelKeyWorkloadName)
+	delete(p.Labels, pod.LabelKeyWorkloadStack)
+	delete(p.Labels, pod.LabelKeyWorkloadSequence)
+	p.Labels[pod.LabelKeyAppLegacy] = "helloworld"
+	p.Labels[pod.LabelKeyStackLegacy] = "test"
+	p.Labels[pod.LabelKeySequenceLegacy] = "v001"
+
+	main := &p.Spec.Containers[0]
+	main.Name = TaskID
+	// Memory and disk in MB, network in Mbps
+	main.Resources.Limits = Resources("2", "4096", "10240", "128")
+	main.Resources.Requests = Resources("2", "4096", "10240", "128")
+
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Resources returns a resource list with the given CPU, memory, disk and network quantities, and no GPU
+func Resources(cpu, memory, disk, network string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:                 resource.MustParse(cpu),
+		corev1.ResourceMemory:              resource.MustParse(memory),
+		corev1.ResourceEphemeralStorage:    resource.MustParse(disk),
+		resourceCommon.ResourceNameGpu:     resource.MustParse("0"),
+		resourceCommon.ResourceNameNetwork: resource.MustParse(network),
+	}
+}
+
+// WithName sets the name of the pod and its task ID label
+func WithName(name string) PodOption {
+	return func(p *corev1.Pod) {
+		p.Name = name
+		p.Labels[pod.LabelKeyTaskId] = name
+	}
+}
+
+// WithAnnotations adds annotations to the pod
+func WithAnnotations(annotations map[string]string) PodOption {
+	return func(p *corev1.Pod) {
+		for k, v := range annotations {
+			p.Annotations[k] = v
+		}
+	}
+}
+
+// WithLabels adds labels to the pod
+func WithLabels(labels map[string]string) PodOption {
+	return func(p *corev1.Pod) {
+		for k, v := range labels {
+			p.Labels[k] = v
+		}
+	}
+}
+
+// WithJobType sets the job type of the pod
+func WithJobType(jobType string) PodOption {
+	return WithAnnotations(map[string]string{pod.AnnotationKeyJobType: jobType})
+}
+
+// WithCapacityGroup sets the capacity group of the pod
+func WithCapacityGroup(capacityGroup string) PodOption {
+	return WithLabels(map[string]string{pod.LabelKeyCapacityGroup: capacityGroup})
+}
+
+// WithNodeName binds the pod to a node
+func WithNodeName(nodeName string) PodOption {
+	return func(p *corev1.Pod) {
+		p.Spec.NodeName = nodeName
+	}
+}
+
+// WithPhase sets the phase of the pod
+func WithPhase(phase corev1.PodPhase) PodOption {
+	return func(p *corev1.Pod) {
+		p.Status.Phase = phase
+	}
+}
+
+// WithResources sets the limits and requests of the main container
+func WithResources(resources corev1.ResourceList) PodOption {
+	return func(p *corev1.Pod) {
+		main := pod.GetMainUserContainer(p)
+		main.Resources.Limits = resources.DeepCopy()
+		main.Resources.Requests = resources.DeepCopy()
+	}
+}
+
+// WithGPUs sets the number of GPUs of the main container, and the matching toleration
+func WithGPUs(gpus int64) PodOption {
+	return func(p *corev1.Pod) {
+		main := pod.GetMainUserContainer(p)
+		quantity := *resource.NewQuantity(gpus, resource.DecimalSI)
+		main.Resources.Limits[resourceCommon.ResourceNameGpu] = quantity
+		main.Resources.Requests[resourceCommon.ResourceNameGpu] = quantity
+		p.Spec.Tolerations = append(p.Spec.Tolerations, corev1.Toleration{
+			Key:      node.TaintKeyGPUNode,
+			Operator: corev1.TolerationOpExists,
+		})
+	}
+}
+
+// WithIPv6 requests an IPv6 address for the pod
+func WithIPv6() PodOption {
+	return WithAnnotations(map[string]string{pod.AnnotationKeyNetworkAssignIPv6Address: strconv.FormatBool(true)})
+}
+
+// WithPlatformSidecar adds a platform sidecar container to the pod, released on the given channel
+func WithPlatformSidecar(name, channel string) PodOption {
+	return func(p *corev1.Pod) {
+		p.Annotations[name+"."+pod.AnnotationKeySuffixSidecars] = "true"
+		p.Annotations[pod.SidecarAnnotation(name, "channel")] = channel
+		p.Annotations[pod.ContainerAnnotation(name, pod.AnnotationKeySuffixContainersSidecar)] = name
+		p.Spec.Containers = append(p.Spec.Containers, corev1.Container{
+			Name:  name,
+			Image: "registry.example.com/titusops/" + name + ":" + channel,
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("128Mi"),
+				},
+			},
+		})
+	}
+}
+
+// WithUserSidecar adds a user sidecar container to the pod
+func WithUserSidecar(name, image string) PodOption {
+	return func(p *corev1.Pod) {
+		p.Spec.Containers = append(p.Spec.Containers, corev1.Container{
+			Name:  name,
+			Image: image,
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("512Mi"),
+				},
+			},
+		})
+	}
+}
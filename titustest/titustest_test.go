@@ -0,0 +1,95 @@
+package titustest
+
+import (
+	"testing"
+
+	"github.com/Netflix/titus-kube-common/node"
+	"github.com/Netflix/titus-kube-common/pod"
+	"github.com/Netflix/titus-kube-common/resourcepool"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPodConfigGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		pod    *corev1.Pod
+	}{
+		{golden: "batch.json", pod: NewBatchPod()},
+		{golden: "service.json", pod: NewServicePod()},
+		{golden: "gpu.json", pod: NewGPUPod(2)},
+		{golden: "ipv6.json", pod: NewIPv6Pod()},
+		{golden: "sidecars.json", pod: NewPodWithSidecars()},
+		{golden: "legacy.json", pod: NewLegacyPod()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			AssertConfigGolden(t, tt.pod, tt.golden)
+			assert.NilError(t, pod.CheckWorkloadIdentity(tt.pod))
+		})
+	}
+}
+
+func TestPodOptions(t *testing.T) {
+	p := NewServicePod(
+		WithName("other-task"),
+		WithCapacityGroup("web"),
+		WithNodeName(InstanceID),
+		WithPhase(corev1.PodRunning),
+	)
+	assert.Equal(t, p.Name, "other-task")
+	assert.Equal(t, p.Labels[pod.LabelKeyTaskId], "other-task")
+	assert.Equal(t, p.Labels[pod.LabelKeyCapacityGroup], "web")
+	assert.Equal(t, p.Annotations[pod.AnnotationKeyJobType], JobTypeService)
+	assert.Equal(t, p.Spec.NodeName, InstanceID)
+
+	// Constructors return independent objects
+	assert.Equal(t, NewServicePod().Name, TaskID)
+}
+
+func TestNodes(t *testing.T) {
+	kubelet := NewKubeletNode()
+	assert.Equal(t, kubelet.Labels[node.LabelKeyBackend], node.LabelValueBackendKubelet)
+	assert.Equal(t, kubelet.Labels[node.LabelKeyResourcePool], resourcepool.ResourcePoolElastic)
+	assert.Equal(t, node.GetLifecycleState(kubelet), node.LifecycleStateActive)
+	assert.Equal(t, len(node.LabelAnnotationMismatches(kubelet)), 0)
+	assert.Equal(t, len(kubelet.Spec.Taints), 0)
+
+	vk := NewVirtualKubeletNode(WithInstanceID("i-0123456789abcdef1"))
+	assert.Equal(t, vk.Name, "i-0123456789abcdef1")
+	assert.Equal(t, vk.Spec.ProviderID, "aws:///us-east-1a/i-0123456789abcdef1")
+	assert.DeepEqual(t, vk.Spec.Taints, []corev1.Taint{
+		{Key: node.TaintKeyBackend, Value: node.LabelValueBackendVirtualKubelet, Effect: corev1.TaintEffectNoSchedule},
+	})
+	conf, err := node.NodeToConfig(vk)
+	assert.NilError(t, err)
+	assert.Equal(t, *conf.InstanceID, "i-0123456789abcdef1")
+
+	mockNode := NewMockNode()
+	assert.Assert(t, node.IsMockNode(mockNode))
+	assert.Equal(t, mockNode.Labels[node.LabelKeyResourcePool], resourcepool.ResourcePoolMockNodes)
+	assert.DeepEqual(t, mockNode.Spec.Taints, []corev1.Taint{
+		{Key: node.TaintKeyBackend, Value: node.LabelValueBackendMock, Effect: corev1.TaintEffectNoSchedule},
+	})
+
+	decommissioning := NewDecommissioningNode()
+	assert.Equal(t, node.GetLifecycleState(decommissioning), node.LifecycleStateDecommissioning)
+	assert.Assert(t, node.CheckLifecycle(decommissioning).Consistent())
+}
+
+func TestPodsFitNodes(t *testing.T) {
+	kubelet := NewKubeletNode()
+	vk := NewVirtualKubeletNode()
+	for _, p := range []*corev1.Pod{NewBatchPod(), NewGPUPod(1), NewPodWithSidecars(), NewLegacyPod()} {
+		ok, untolerated := pod.ToleratesNodeTaints(p, kubelet)
+		assert.Assert(t, ok, "pod %s does not tolerate %v", p.Name, untolerated)
+
+		ok, _ = pod.ToleratesNodeTaints(p, vk)
+		assert.Assert(t, !ok, "pod %s tolerates the Virtual Kubelet backend taint", p.Name)
+		p.Spec.Tolerations = append(p.Spec.Tolerations,
+			pod.TolerationsFor(&pod.Config{}, pod.TolerationOptions{Backend: node.LabelValueBackendVirtualKubelet})...)
+		ok, untolerated = pod.ToleratesNodeTaints(p, vk)
+		assert.Assert(t, ok, "pod %s does not tolerate %v", p.Name, untolerated)
+	}
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Netflix/titus-kube-common/pod"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// PodDefaulterPath is the default path the pod defaulting webhook is served on
+const PodDefaulterPath = "/mutate-v1-pod-defaults"
+
+var (
+	_ admission.Handler = (*PodDefaulter)(nil)
+)
+
+// PodDefaulter is a mutating admission handler that applies pod.Defaults to pods on creation and update,
+// and responds with the resulting JSON patch
+type PodDefaulter struct {
+	decoder  *admission.Decoder
+	defaults pod.Defaults
+}
+
+// NewPodDefaulter creates a handler applying the given defaults. Use pod.StandardDefaults()
+// for the standard Titus defaults.
+func NewPodDefaulter(defaults pod.Defaults) (*PodDefaulter, error) {
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PodDefaulter{
+		decoder:  decoder,
+		defaults: defaults,
+	}, nil
+}
+
+// SetupWithManager registers the handler on the manager's webhook server under the given path
+func (d *PodDefaulter) SetupWithManager(mgr manager.Manager, path string) {
+	mgr.GetWebhookServer().Register(path, &admission.Webhook{Handler: d})
+}
+
+func (d *PodDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	p := &corev1.Pod{}
+	if err := d.decoder.Decode(req, p); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	d.defaults.Apply(p)
+
+	marshaled, err := json.Marshal(p)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Netflix/titus-kube-common/pod"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func admissionRequest(t *testing.T, operation admissionv1.Operation, p *corev1.Pod) admission.Request {
+	raw, err := json.Marshal(p)
+	require.NoError(t, err)
+
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+func TestPodDefaulterHandle(t *testing.T) {
+	d, err := NewPodDefaulter(pod.StandardDefaults())
+	require.NoError(t, err)
+
+	p := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			Annotations: map[string]string{
+				pod.AnnotationKeyNetworkSecurityGroups: "sg-2 , sg-1",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "main"}},
+		},
+	}
+
+	resp := d.Handle(context.Background(), admissionRequest(t, admissionv1.Create, p))
+	require.True(t, resp.Allowed)
+	require.Equal(t, admissionv1.PatchTypeJSONPatch, *resp.PatchType)
+
+	patched := map[string]string{}
+	for _, patch := range resp.Patches {
+		if patch.Path == "/metadata/annotations/network.netflix.com~1security-groups" {
+			patched[patch.Operation] = patch.Value.(string)
+		}
+	}
+	require.Equal(t, map[string]string{"replace": "sg-1,sg-2"}, patched)
+}
+
+func TestPodDefaulterNoChanges(t *testing.T) {
+	d, err := NewPodDefaulter(pod.StandardDefaults())
+	require.NoError(t, err)
+
+	p := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "main"}},
+		},
+	}
+	pod.ApplyDefaults(p)
+
+	resp := d.Handle(context.Background(), admissionRequest(t, admissionv1.Create, p))
+	require.True(t, resp.Allowed)
+	require.Empty(t, resp.Patches)
+	require.Nil(t, resp.PatchType)
+}
+
+func TestPodDefaulterInvalidObject(t *testing.T) {
+	d, err := NewPodDefaulter(pod.StandardDefaults())
+	require.NoError(t, err)
+
+	req := admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: []byte("{not json")},
+		},
+	}
+	resp := d.Handle(context.Background(), req)
+	require.False(t, resp.Allowed)
+	require.Equal(t, int32(400), resp.Result.Code)
+}